@@ -1,89 +1,128 @@
 /*
+	Go base64 -- base64 encode/decode data and print to standard output
+
+	Copyright (C) 2015 Eric Lagergren
+
 	This program is free software: you can redistribute it and/or modify
-	it under the terms of the GNU General Public License version 3 as
-	published by the Free Software Foundation.
-	
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
 	This program is distributed in the hope that it will be useful,
 	but WITHOUT ANY WARRANTY; without even the implied warranty of
 	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
 	GNU General Public License for more details.
-	
+
 	You should have received a copy of the GNU General Public License
 	along with this program.  If not, see <http://www.gnu.org/licenses/>.
 */
 
+/*
+	Written by Eric Lagergren <ericscottlagergren@gmail.com>
+	Inspired by GNU's base64, which was written by Simon Josefsson.
+*/
+
 package main
 
 import (
-	"encoding/base64"
-	"flag"
+	"bufio"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
 	"os"
-	"unicode"
+
+	"github.com/EricLagerg/go-coreutils/internal/closeout"
+	"github.com/EricLagerg/go-coreutils/internal/codec"
+	"github.com/EricLagerg/go-coreutils/internal/quote"
+	flag "github.com/ogier/pflag"
 )
 
-func base64Encode(src []byte) []byte {
-	return []byte(base64.StdEncoding.EncodeToString(src))
-}
+const (
+	Help = `Usage: base64 [OPTION]... [FILE]
+Base64 encode or decode FILE, or standard input, to standard output.
 
-func base64Decode(src []byte) ([]byte, error) {
-	return base64.StdEncoding.DecodeString(string(src))
-}
+With no FILE, or when FILE is -, read standard input.
 
-func readData(reader io.Reader) ([]byte, error) {
-	return ioutil.ReadAll(reader)
-}
+Mandatory arguments to long options are mandatory for short options too.
+  -d, --decode          decode data
+  -i, --ignore-garbage  when decoding, ignore non-alphabet characters
+  -w, --wrap=COLS       wrap encoded lines after COLS character (default 76).
+                          Use 0 to disable line wrapping
 
-func isAlpha(ch byte) bool {
-	return unicode.IsLetter(rune(ch))
-}
+      --help     display this help and exit
+      --version  output version information and exit
 
-func readAndHandle(reader io.Reader, flagDecode *bool, flagIgnore *bool) {
-	src, err := readData(reader)
-	checkError(err)
-	var toHandle []byte
-	if *flagIgnore {
-		//It seems that the effect of "base64 -i" in *nix
-		//is not filter the non-alphabet charater.
-		//This flag cannot work as the original *nix command flag.
-		for i := 0; i < len(src); i++ {
-			if isAlpha(src[i]) {
-				toHandle = append(toHandle, src[i])
-			}
-		}
-	} else {
-		toHandle = src
+The data are encoded as described for the base64 alphabet in RFC 4648.
+When decoding, the input may contain newlines in addition to the bytes of
+the formal base64 alphabet.  Use --ignore-garbage to attempt to recover
+from any other non-alphabet bytes in the encoded stream.
+
+Report base64 bugs to ericscottlagergren@gmail.com
+Go coreutils home page: <https://www.github.com/EricLagerg/go-coreutils/>
+`
+	Version = `base64 (Go coreutils) 2.0
+Copyright (C) 2015 Eric Lagergren
+License GPLv3+: GNU GPL version 3 or later <http://gnu.org/licenses/gpl.html>.
+This is free software: you are free to change and redistribute it.
+There is NO WARRANTY, to the extent permitted by law.
+
+Written by Eric Lagergren <ericscottlagergren@gmail.com>
+`
+)
+
+var (
+	decode  = flag.BoolP("decode", "d", false, "decode data")
+	ignore  = flag.BoolP("ignore-garbage", "i", false, "ignore non-alphabet characters")
+	wrap    = flag.IntP("wrap", "w", 76, "wrap encoded lines after COLS characters")
+	version = flag.Bool("version", false, "print program's version")
+
+	fatal = log.New(os.Stderr, "base64: ", 0)
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s", Help)
+		os.Exit(1)
 	}
-	if *flagDecode {
-		decoded, err := base64Decode(toHandle)
-		checkError(err)
-		fmt.Printf("%s", string(decoded))
-	} else {
-		encoded := base64Encode(toHandle)
-		fmt.Printf("%s\n", string(encoded))
+	flag.Parse()
+
+	if *version {
+		fmt.Printf("%s", Version)
+		os.Exit(0)
 	}
-}
-func checkError(err error) {
-	if err != nil {
-		log.Fatal(err)
+
+	if *wrap < 0 {
+		fatal.Fatalf("invalid wrap size: '%d'\n", *wrap)
 	}
-}
 
-func main() {
-	flagDecode := flag.Bool("d", false, "Decode the data")
-	flagIgnore := flag.Bool("i", false, "When decoding, ignore non-alphabet characters")
-	//TODO: -w
-	flag.Parse()
-	if len(flag.Args()) == 0 {
-		readAndHandle(os.Stdin, flagDecode, flagIgnore)
-	} else {
-		for i := 0; i < len(flag.Args()); i++ {
-			file, err := os.Open(flag.Args()[i])
-			checkError(err)
-			readAndHandle(file, flagDecode, flagIgnore)
+	if flag.NArg() > 1 {
+		fatal.Printf("extra operand %s\n", quote.Name(flag.Arg(1)))
+		fatal.Fatalln("Try 'base64 --help' for more information.")
+	}
+
+	var in io.Reader = os.Stdin
+	if name := flag.Arg(0); name != "" && name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			fatal.Fatalf("%s: %v\n", quote.File(name), err.(*os.PathError).Err)
 		}
+		defer file.Close()
+		in = file
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+
+	var err error
+	if *decode {
+		err = codec.Decode(out, in, codec.Base64, *ignore)
+	} else {
+		err = codec.Encode(out, in, codec.Base64, *wrap)
+	}
+
+	if cerr := closeout.Stdout(out); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fatal.Fatalln(err)
 	}
 }
@@ -14,21 +14,121 @@
 
 package main
 
-import "fmt"
-import "flag"
-import "path"
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/EricLagerg/go-coreutils/internal/closeout"
+	"github.com/EricLagerg/go-coreutils/internal/quote"
+	flag "github.com/ogier/pflag"
+)
+
+const (
+	Help = `Usage: basename NAME [SUFFIX]
+  or:  basename OPTION... NAME...
+Print NAME with any leading directory components removed.
+If specified, also remove a trailing SUFFIX.
+
+Mandatory arguments to long options are mandatory for short options too.
+  -a, --multiple       support multiple arguments and treat each as a NAME
+  -s, --suffix=SUFFIX  remove a trailing SUFFIX; implies -a
+  -z, --zero           end each output line with NUL, not newline
+      --help     display this help and exit
+      --version  output version information and exit
+
+Examples:
+  basename /usr/bin/sort          -> "sort"
+  basename include/stdio.h .h     -> "stdio"
+  basename -s .h include/stdio.h  -> "stdio"
+  basename -a any/str1 any/str2   -> "str1" followed by "str2"
+
+Report basename bugs to ericscottlagergren@gmail.com
+Go coreutils home page: <https://www.github.com/EricLagerg/go-coreutils/>
+`
+	Version = `basename (Go coreutils) 2.0
+License GPLv3: GNU GPL version 3 <http://gnu.org/licenses/gpl.html>.
+This is free software: you are free to change and redistribute it.
+There is NO WARRANTY, to the extent permitted by law.
+`
+)
+
+var (
+	multiple = flag.BoolP("multiple", "a", false, "")
+	suffix   = flag.StringP("suffix", "s", "", "")
+	zero     = flag.BoolP("zero", "z", false, "")
+	version  = flag.Bool("version", false, "")
+
+	fatal = log.New(os.Stderr, "basename: ", 0)
+)
+
+// baseName returns name without its leading directory components and
+// with suffix removed, following POSIX: trailing slashes don't count, a
+// name made only of slashes is "/", and suffix is kept if it's the whole
+// result.
+func baseName(name, suffix string) string {
+	if name == "" {
+		return ""
+	}
+	name = strings.TrimRight(name, "/")
+	if name == "" {
+		return "/"
+	}
+
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if suffix != name && strings.HasSuffix(name, suffix) {
+		name = name[:len(name)-len(suffix)]
+	}
+	return name
+}
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s", Help)
+		os.Exit(1)
+	}
 	flag.Parse()
-	if len(flag.Args()) == 1 {
-		fmt.Println(path.Base(flag.Arg(0)))
-	} else if len(flag.Args()) == 2 {
-		base   := path.Base(flag.Arg(0))
-		suffix := flag.Arg(1)
-		if base[len(base)-len(suffix):] == suffix {
-			fmt.Println(base[:len(base)-len(suffix)])
-		} else {
-			fmt.Println(base)
+
+	if *version {
+		fmt.Printf("%s", Version)
+		os.Exit(0)
+	}
+
+	if flag.NArg() == 0 {
+		fatal.Printf("missing operand\n")
+		fatal.Fatalln("Try 'basename --help' for more information.")
+	}
+
+	names := flag.Args()
+	if *suffix != "" {
+		*multiple = true
+	}
+	if !*multiple {
+		switch flag.NArg() {
+		case 1:
+		case 2:
+			*suffix, names = flag.Arg(1), names[:1]
+		default:
+			fatal.Printf("extra operand %s\n", quote.Name(flag.Arg(2)))
+			fatal.Fatalln("Try 'basename --help' for more information.")
 		}
 	}
+
+	eol := byte('\n')
+	if *zero {
+		eol = 0
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	for _, name := range names {
+		out.WriteString(baseName(name, *suffix))
+		out.WriteByte(eol)
+	}
+	if err := closeout.Stdout(out); err != nil {
+		fatal.Fatalln(err)
+	}
 }
@@ -0,0 +1,180 @@
+/*
+	Go basenc -- encode/decode data and print to standard output
+
+	Copyright (C) 2015 Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+	Written by Eric Lagergren <ericscottlagergren@gmail.com>
+	Inspired by GNU's basenc, which was written by Simon Josefsson and
+	Assaf Gordon.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/EricLagerg/go-coreutils/internal/closeout"
+	"github.com/EricLagerg/go-coreutils/internal/codec"
+	"github.com/EricLagerg/go-coreutils/internal/quote"
+	flag "github.com/ogier/pflag"
+)
+
+const (
+	Help = `Usage: basenc [OPTION]... [FILE]
+basenc encode or decode FILE, or standard input, to standard output.
+
+With no FILE, or when FILE is -, read standard input.
+
+Mandatory arguments to long options are mandatory for short options too.
+      --base64          same as 'base64' program (RFC4648 section 4)
+      --base64url       file- and url-safe base64 (RFC4648 section 5)
+      --base32          same as 'base32' program (RFC4648 section 6)
+      --base32hex       extended hex alphabet base32 (RFC4648 section 7)
+      --base16          hex encoding (RFC4648 section 8)
+      --base2msbf       bit string with most significant bit (msb) first
+      --base2lsbf       bit string with least significant bit (lsb) first
+  -d, --decode          decode data
+  -i, --ignore-garbage  when decoding, ignore non-alphabet characters
+  -w, --wrap=COLS       wrap encoded lines after COLS character (default 76).
+                          Use 0 to disable line wrapping
+
+      --z85             ascii85-like encoding (ZeroMQ spec:32/Z85);
+                        when encoding, input length must be a multiple of 4;
+                        when decoding, input length must be a multiple of 5
+      --help     display this help and exit
+      --version  output version information and exit
+
+When decoding, the input may contain newlines in addition to the bytes of
+the formal alphabet.  Use --ignore-garbage to attempt to recover
+from any other non-alphabet bytes in the encoded stream.
+
+Report basenc bugs to ericscottlagergren@gmail.com
+Go coreutils home page: <https://www.github.com/EricLagerg/go-coreutils/>
+`
+	Version = `basenc (Go coreutils) 1.0
+Copyright (C) 2015 Eric Lagergren
+License GPLv3+: GNU GPL version 3 or later <http://gnu.org/licenses/gpl.html>.
+This is free software: you are free to change and redistribute it.
+There is NO WARRANTY, to the extent permitted by law.
+
+Written by Eric Lagergren <ericscottlagergren@gmail.com>
+`
+)
+
+// Encoding selectors, in the order they're listed in --help.
+var encodings = []struct {
+	name string
+	enc  codec.Encoding
+}{
+	{"base64", codec.Base64},
+	{"base64url", codec.Base64URL},
+	{"base32", codec.Base32},
+	{"base32hex", codec.Base32Hex},
+	{"base16", codec.Base16},
+	{"base2msbf", codec.Base2MSBF},
+	{"base2lsbf", codec.Base2LSBF},
+	{"z85", codec.Z85},
+}
+
+// The encoding selected on the command line.
+var enc codec.Encoding
+
+// encFlag is a boolean flag that selects an encoding. They all set enc,
+// so like GNU the last one given wins.
+type encFlag struct{ enc codec.Encoding }
+
+func (f encFlag) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if v {
+		enc = f.enc
+	}
+	return err
+}
+
+func (encFlag) String() string   { return "false" }
+func (encFlag) IsBoolFlag() bool { return true }
+
+var (
+	decode  = flag.BoolP("decode", "d", false, "decode data")
+	ignore  = flag.BoolP("ignore-garbage", "i", false, "ignore non-alphabet characters")
+	wrap    = flag.IntP("wrap", "w", 76, "wrap encoded lines after COLS characters")
+	version = flag.Bool("version", false, "print program's version")
+
+	fatal = log.New(os.Stderr, "basenc: ", 0)
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s", Help)
+		os.Exit(1)
+	}
+	for _, e := range encodings {
+		flag.Var(encFlag{e.enc}, e.name, "")
+	}
+	flag.Parse()
+
+	if *version {
+		fmt.Printf("%s", Version)
+		os.Exit(0)
+	}
+
+	if enc == nil {
+		fatal.Printf("missing encoding type\n")
+		fatal.Fatalln("Try 'basenc --help' for more information.")
+	}
+
+	if *wrap < 0 {
+		fatal.Fatalf("invalid wrap size: '%d'\n", *wrap)
+	}
+
+	if flag.NArg() > 1 {
+		fatal.Printf("extra operand %s\n", quote.Name(flag.Arg(1)))
+		fatal.Fatalln("Try 'basenc --help' for more information.")
+	}
+
+	var in io.Reader = os.Stdin
+	if name := flag.Arg(0); name != "" && name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			fatal.Fatalf("%s: %v\n", quote.File(name), err.(*os.PathError).Err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+
+	var err error
+	if *decode {
+		err = codec.Decode(out, in, enc, *ignore)
+	} else {
+		err = codec.Encode(out, in, enc, *wrap)
+	}
+
+	if cerr := closeout.Stdout(out); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fatal.Fatalln(err)
+	}
+}
@@ -1,3 +1,5 @@
+// +build !windows
+
 /*
 	Go cat - concatenate files and print on the standard output.
 	Copyright (C) 2014 Eric Lagergren
@@ -30,8 +32,10 @@ import (
 	"os"
 	"syscall"
 
-	"golang.org/x/sys/unix"
-
+	"github.com/EricLagerg/go-coreutils/internal/closeout"
+	"github.com/EricLagerg/go-coreutils/internal/fadvise"
+	"github.com/EricLagerg/go-coreutils/internal/noatime"
+	"github.com/EricLagerg/go-coreutils/internal/quote"
 	flag "github.com/ogier/pflag"
 )
 
@@ -49,6 +53,9 @@ Concatenate FILE(s), or standard input, to standard output.
   -T, --show-tabs          display TAB characters as ^I
   -u                       (ignored)
   -v, --show-nonprinting   use ^ and M- notation, except for LFD and TAB
+      --throttle=RATE      write at most RATE bytes per second; RATE may have
+                           a K, M, G or T suffix (powers of 1024) or KB, MB,
+                           GB or TB (powers of 1000), e.g. 10M/s
       --Help     display this Help and exit
       --version  output version information and exit
 
@@ -81,6 +88,7 @@ var (
 	tabs       = flag.BoolP("show-tabs", "T", false, "")
 	nonPrint   = flag.BoolP("non-printing", "v", false, "")
 	unbuffered = flag.BoolP("unbuffered", "u", false, "")
+	throttleTo = flag.String("throttle", "", "")
 	version    = flag.Bool("version", false, "")
 
 	totalNewline    int64
@@ -369,6 +377,20 @@ func main() {
 	if *all || *npEnds || *npTabs || *nonPrint {
 		showNonPrinting = true
 	}
+	if !(*number || *ends || *squeeze || *tabs || showNonPrinting) {
+		simple = true
+	}
+
+	// Everything goes through out, which is only something other than
+	// stdout when the rate is limited.
+	var out io.Writer = os.Stdout
+	if *throttleTo != "" {
+		rate, err := parseRate(*throttleTo)
+		if err != nil {
+			fatal.Fatalln(err)
+		}
+		out = newThrottle(os.Stdout, rate)
+	}
 
 	outStat, err := os.Stdout.Stat()
 	if err != nil {
@@ -392,7 +414,7 @@ func main() {
 		if arg == "-" {
 			file = os.Stdin
 		} else {
-			file, err = os.Open(arg)
+			file, err = noatime.Open(arg)
 			if err != nil {
 				fatal.Fatalln(err)
 			}
@@ -403,12 +425,12 @@ func main() {
 			fatal.Fatalln(err)
 		}
 		if inStat.IsDir() {
-			fatal.Printf("%s: Is a directory\n", file.Name())
+			fatal.Printf("%s: Is a directory\n", quote.File(file.Name()))
 		}
 		inBsize := int(inStat.Sys().(*syscall.Stat_t).Blksize)
 
 		// prefetch! prefetch! prefetch!
-		unix.Fadvise(int(file.Fd()), 0, 0, unix.FADV_SEQUENTIAL)
+		fadvise.Sequential(file)
 
 		// Make sure we're not catting a file to itself,
 		// provided it's a regular file. Catting a non-reg
@@ -416,19 +438,40 @@ func main() {
 		// e.g. cat file > file
 		if outReg && os.SameFile(outStat, inStat) {
 			if n, _ := file.Seek(0, os.SEEK_CUR); n < inStat.Size() {
-				fatal.Fatalf("%s: input file is output file\n", file.Name())
+				fatal.Fatalf("%s: input file is output file\n", quote.File(file.Name()))
 			}
 		}
 
 		if simple {
-			// Select larger block size
-			size := max(inBsize, outBsize)
-			outBuf := bufio.NewWriterSize(os.Stdout, size)
-			ok ^= simpleCat(file, outBuf)
-
-			// Flush because we don't have a chance to in
-			// simpleCat() because we use io.Copy()
-			outBuf.Flush()
+			// Pure pass-through, so let the kernel move the data,
+			// unless the rate is limited.
+			handled := false
+			if out == io.Writer(os.Stdout) {
+				handled, err = zeroCopy(file, os.Stdout, inStat, outStat)
+			}
+			if handled {
+				if err == syscall.EPIPE {
+					// The kernel copy goes around os.File, so the
+					// runtime never saw the broken pipe.
+					closeout.DieOfSIGPIPE()
+					fatal.Fatalln(closeout.WriteError(err))
+				}
+				if err != nil {
+					fatal.Printf("%s: %v\n", quote.File(file.Name()), err)
+					ok = 1
+				}
+			} else {
+				// Select larger block size
+				size := max(inBsize, outBsize)
+				outBuf := bufio.NewWriterSize(out, size)
+				ok ^= simpleCat(file, outBuf)
+
+				// Flush because we don't have a chance to in
+				// simpleCat() because we use io.Copy()
+				if err := outBuf.Flush(); err != nil {
+					fatal.Fatalln(closeout.WriteError(err))
+				}
+			}
 		} else {
 			// If you want to know why, exactly, I chose
 			// outBsize -1 + inBsize*4 + 20, read GNU's cat
@@ -437,13 +480,26 @@ func main() {
 			// the control characters (M-^), and outBsize is
 			// due to new tests for newlines.
 			size := outBsize - 1 + inBsize*4 + 20
-			outBuf := bufio.NewWriterSize(os.Stdout, size)
+			outBuf := bufio.NewWriterSize(out, size)
 			inBuf := make([]byte, inBsize+1)
 			ok ^= cat(file, inBuf, outBuf)
+
+			// cat() flushes as it goes but a failed write sticks,
+			// so this picks up any of them.
+			if err := outBuf.Flush(); err != nil {
+				fatal.Fatalln(closeout.WriteError(err))
+			}
 		}
 
+		// Whatever we just read isn't likely to be read again soon.
+		if inStat.Mode().IsRegular() {
+			fadvise.DontNeed(file)
+		}
 		file.Close()
 	}
 
+	if err := closeout.Stdout(nil); err != nil {
+		fatal.Fatalln(err)
+	}
 	os.Exit(ok)
 }
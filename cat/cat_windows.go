@@ -30,6 +30,8 @@ import (
 	"os"
 	"syscall"
 
+	"github.com/EricLagerg/go-coreutils/internal/closeout"
+	"github.com/EricLagerg/go-coreutils/internal/quote"
 	k32 "github.com/EricLagerg/go-gnulib/windows"
 	flag "github.com/ogier/pflag"
 )
@@ -48,6 +50,9 @@ Concatenate FILE(s), or standard input, to standard output.
   -T, --show-tabs          display TAB characters as ^I
   -u                       (ignored)
   -v, --show-nonprinting   use ^ and M- notation, except for LFD and TAB
+      --throttle=RATE      write at most RATE bytes per second; RATE may have
+                           a K, M, G or T suffix (powers of 1024) or KB, MB,
+                           GB or TB (powers of 1000), e.g. 10M/s
       --Help     display this Help and exit
       --version  output version information and exit
 
@@ -80,6 +85,7 @@ var (
 	tabs       = flag.BoolP("show-tabs", "T", false, "")
 	nonPrint   = flag.BoolP("non-printing", "v", false, "")
 	unbuffered = flag.BoolP("unbuffered", "u", false, "")
+	throttleTo = flag.String("throttle", "", "")
 	version    = flag.Bool("version", false, "")
 
 	totalNewline    int64
@@ -362,6 +368,17 @@ func main() {
 		showNonPrinting = true
 	}
 
+	// Everything goes through out, which is only something other than
+	// stdout when the rate is limited.
+	var out io.Writer = os.Stdout
+	if *throttleTo != "" {
+		rate, err := parseRate(*throttleTo)
+		if err != nil {
+			fatal.Fatalln(err)
+		}
+		out = newThrottle(os.Stdout, rate)
+	}
+
 	outHandle := syscall.Handle(os.Stdout.Fd())
 	outType, err := syscall.GetFileType(outHandle)
 	if err != nil {
@@ -395,7 +412,7 @@ func main() {
 			fatal.Fatalln(err)
 		}
 		if inStat.IsDir() {
-			fatal.Printf("%s: Is a directory\n", file.Name())
+			fatal.Printf("%s: Is a directory\n", quote.File(file.Name()))
 		}
 		inHandle := syscall.Handle(file.Fd())
 		inBsize := 4096
@@ -423,18 +440,20 @@ func main() {
 
 			if string(inPath) == string(outPath) {
 				if n, _ := file.Seek(0, os.SEEK_CUR); n < inStat.Size() {
-					fatal.Fatalf("%s: input file is output file\n", file.Name())
+					fatal.Fatalf("%s: input file is output file\n", quote.File(file.Name()))
 				}
 			}
 		}
 
 		if simple {
-			outBuf := bufio.NewWriterSize(os.Stdout, 4096)
+			outBuf := bufio.NewWriterSize(out, 4096)
 			ok ^= simpleCat(file, outBuf)
 
 			// Flush because we don't have a chance to in
 			// simpleCat() because we use io.Copy()
-			outBuf.Flush()
+			if err := outBuf.Flush(); err != nil {
+				fatal.Fatalln(closeout.WriteError(err))
+			}
 		} else {
 			// If you want to know why, exactly, I chose
 			// outBsize -1 + inBsize*4 + 20, read GNU's cat
@@ -443,13 +462,22 @@ func main() {
 			// the control characters (M-^), and outBsize is
 			// due to new tests for newlines.
 			size := outBsize - 1 + inBsize*4 + 20
-			outBuf := bufio.NewWriterSize(os.Stdout, size)
+			outBuf := bufio.NewWriterSize(out, size)
 			inBuf := make([]byte, inBsize+1)
 			ok ^= cat(file, inBuf, outBuf)
+
+			// cat() flushes as it goes but a failed write sticks,
+			// so this picks up any of them.
+			if err := outBuf.Flush(); err != nil {
+				fatal.Fatalln(closeout.WriteError(err))
+			}
 		}
 
 		file.Close()
 	}
 
+	if err := closeout.Stdout(nil); err != nil {
+		fatal.Fatalln(err)
+	}
 	os.Exit(ok)
 }
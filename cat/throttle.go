@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// throttle is an io.Writer that passes at most rate bytes per second on
+// to w.
+type throttle struct {
+	w     io.Writer
+	rate  int64
+	start time.Time
+	n     int64 // bytes written since start
+}
+
+func newThrottle(w io.Writer, rate int64) *throttle {
+	return &throttle{w: w, rate: rate, start: time.Now()}
+}
+
+func (t *throttle) Write(p []byte) (int, error) {
+	// Write at most a tenth of a second's worth at a time so the output
+	// is steady instead of arriving in large bursts.
+	chunk := int(t.rate / 10)
+	if chunk < 1 {
+		chunk = 1
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := len(p)
+		if n > chunk {
+			n = chunk
+		}
+		m, err := t.w.Write(p[:n])
+		written += m
+		t.n += int64(m)
+		if err != nil {
+			return written, err
+		}
+		p = p[m:]
+
+		now := time.Now()
+		due := t.start.Add(time.Duration(float64(t.n) / float64(t.rate) * float64(time.Second)))
+		if d := due.Sub(now); d > 0 {
+			time.Sleep(d)
+		} else if d < -time.Second {
+			// The input stalled (e.g. an interactive stdin). Don't
+			// let the idle time build up credit for a burst later.
+			t.start, t.n = now, 0
+		}
+	}
+	return written, nil
+}
+
+// parseRate parses a --throttle argument: a positive integer optionally
+// followed by a multiplier, K, M, G or T for powers of 1024 (KiB etc.
+// also work) and KB, MB, GB or TB for powers of 1000, and optionally
+// "/s".
+func parseRate(s string) (int64, error) {
+	invalid := fmt.Errorf("invalid throttle rate '%s'", s)
+
+	num := strings.TrimSuffix(s, "/s")
+	i := 0
+	for i < len(num) && num[i] >= '0' && num[i] <= '9' {
+		i++
+	}
+	n, err := strconv.ParseInt(num[:i], 10, 64)
+	if err != nil || n <= 0 {
+		return 0, invalid
+	}
+
+	suffix := num[i:]
+	if suffix == "" || suffix == "B" {
+		return n, nil
+	}
+
+	exp := strings.IndexByte("KMGT", suffix[0]) + 1
+	if exp == 0 {
+		return 0, invalid
+	}
+	var base int64
+	switch suffix[1:] {
+	case "", "iB":
+		base = 1024
+	case "B":
+		base = 1000
+	default:
+		return 0, invalid
+	}
+
+	for ; exp > 0; exp-- {
+		if n > (1<<63-1)/base {
+			return 0, invalid
+		}
+		n *= base
+	}
+	return n, nil
+}
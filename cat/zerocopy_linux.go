@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Largest request handed to the kernel at once; sendfile(2) won't move
+// more than 0x7ffff000 bytes per call anyway.
+const maxChunk = 0x7ffff000
+
+// A kernel-side copy method. It returns the number of bytes copied,
+// with 0 meaning EOF.
+type copyFunc func(in, out int) (int64, error)
+
+func copyFileRange(in, out int) (int64, error) {
+	n, err := unix.CopyFileRange(in, nil, out, nil, maxChunk, 0)
+	return int64(n), err
+}
+
+func splice(in, out int) (int64, error) {
+	n, err := unix.Splice(in, nil, out, nil, maxChunk,
+		unix.SPLICE_F_MOVE|unix.SPLICE_F_MORE)
+	return int64(n), err
+}
+
+func sendfile(in, out int) (int64, error) {
+	n, err := unix.Sendfile(out, in, nil, maxChunk)
+	return int64(n), err
+}
+
+// unsupported reports whether err means the method can't be used for this
+// pair of files (old kernel, wrong file types, O_APPEND output, crossing
+// filesystems, ...) rather than a genuine I/O error.
+func unsupported(err error) bool {
+	switch err {
+	case unix.ENOSYS, unix.EINVAL, unix.EXDEV, unix.EOPNOTSUPP,
+		unix.EBADF, unix.EPERM, unix.EAGAIN:
+		return true
+	}
+	return false
+}
+
+// zeroCopy copies in to out without passing the data through user space.
+// The methods are tried from the most to the least specific:
+// copy_file_range(2) between regular files, splice(2) when either end is a
+// pipe, and sendfile(2) from a regular file. Offsets aren't passed, so the
+// files' current positions are used and updated, just as with read/write.
+//
+// If no method works before any data has been written, or the first copy
+// finds nothing, zeroCopy returns false and the caller should fall back
+// to an ordinary copy.
+func zeroCopy(in, out *os.File, inStat, outStat os.FileInfo) (bool, error) {
+	var methods []copyFunc
+
+	inReg := inStat.Mode().IsRegular()
+	if inReg && outStat.Mode().IsRegular() {
+		methods = append(methods, copyFileRange)
+	}
+	if inStat.Mode()&os.ModeNamedPipe != 0 ||
+		outStat.Mode()&os.ModeNamedPipe != 0 {
+		methods = append(methods, splice)
+	}
+	if inReg {
+		methods = append(methods, sendfile)
+	}
+
+	inFd, outFd := int(in.Fd()), int(out.Fd())
+	for _, method := range methods {
+		written := int64(0)
+		for {
+			n, err := method(inFd, outFd)
+			if err == unix.EINTR {
+				continue
+			}
+			if err != nil {
+				if written == 0 && unsupported(err) {
+					break
+				}
+				return true, err
+			}
+			if n == 0 {
+				// Files in /proc and /sys claim to be empty but
+				// aren't, so like GNU, only believe EOF after
+				// something's been copied and let read(2) decide
+				// otherwise.
+				return written > 0, nil
+			}
+			written += n
+		}
+	}
+	return false, nil
+}
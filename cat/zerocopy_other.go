@@ -0,0 +1,11 @@
+// +build !linux,!windows
+
+package main
+
+import "os"
+
+// zeroCopy always declines: the kernel copy interfaces cat uses are
+// Linux-only, so callers fall back to an ordinary read/write loop.
+func zeroCopy(in, out *os.File, inStat, outStat os.FileInfo) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,206 @@
+/*
+	Go chgrp -- change group ownership of files
+
+	Copyright (C) 2015 Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+	Written by Eric Lagergren <ericscottlagergren@gmail.com>
+	Inspired by GNU's chgrp, which was written by David MacKenzie and
+	Jim Meyering.
+*/
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/EricLagerg/go-coreutils/chown/chownlib"
+	"github.com/EricLagerg/go-coreutils/internal/quote"
+	"github.com/EricLagerg/go-coreutils/internal/strerror"
+	flag "github.com/ogier/pflag"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	HELP = `Usage: chgrp [OPTION]... GROUP FILE...
+  or:  chgrp [OPTION]... --reference=RFILE FILE...
+Change the group of each FILE to GROUP.
+With --reference, change the group of each FILE to that of RFILE.
+
+  -c, --changes          like verbose but report only when a change is made
+  -f, --silent, --quiet  suppress most error messages
+  -v, --verbose          output a diagnostic for every file processed
+      --dereference      affect the referent of each symbolic link (this is
+                         the default), rather than the symbolic link itself
+  -h, --no-dereference   affect symbolic links instead of any referenced file
+                         (useful only on systems that can change the
+                         ownership of a symlink)
+      --no-preserve-root  do not treat '/' specially (the default)
+      --preserve-root    fail to operate recursively on '/'
+      --reference=RFILE  use RFILE's group rather than specifying a
+                         GROUP value
+  -R, --recursive        operate on files and directories recursively
+
+The following options modify how a hierarchy is traversed when the -R
+option is also specified.  If more than one is specified, only the final
+one takes effect.
+
+  -H                     if a command line argument is a symbolic link
+                         to a directory, traverse it
+  -L                     traverse every symbolic link to a directory
+                         encountered
+  -P                     do not traverse any symbolic links (default)
+
+      --help     display this help and exit
+      --version  output version information and exit
+
+Examples:
+  chgrp staff /u      Change the group of /u to "staff".
+  chgrp -hR staff /u  Change the group of /u and subfiles to "staff".
+
+Report chgrp bugs to ericscottlagergren@gmail.com
+Go coreutils home page: <https://www.github.com/EricLagerg/go-coreutils/>`
+	Version = `chgrp (Go coreutils) 1.0
+Copyright (C) 2015 Eric Lagergren
+License GPLv3+: GNU GPL version 3 or later <http://gnu.org/licenses/gpl.html>.
+This is free software: you are free to change and redistribute it.
+There is NO WARRANTY, to the extent permitted by law.
+
+Written by Eric Lagergren.
+Inspired by David MacKenzie and Jim Meyering.`
+)
+
+var (
+	changes   = flag.BoolP("changes", "c", false, "verbose but for changes")
+	deref     = flag.Bool("dereference", true, "affect sym link referent")
+	noderef   = flag.BoolP("no-dereference", "h", false, "affect sym link rather than linked file")
+	silent    = flag.BoolP("silent", "f", false, "suppress most error messages")
+	silent2   = flag.Bool("quiet", false, "suppress most error messages")
+	rfile     = flag.String("reference", "", "use RFILE's group")
+	recursive = flag.BoolP("recursive", "R", false, "operate recursively")
+	verbose   = flag.BoolP("verbose", "v", false, "diagnostic for each file")
+	help      = flag.Bool("help", false, "print help")
+	version   = flag.Bool("version", false, "print program's version\n")
+
+	// Set by -H, -L and -P, and by --[no-]preserve-root, which override
+	// each other.
+	traversal    = chownlib.Physical
+	preserveRoot = false
+
+	fatal = log.New(os.Stderr, "chgrp: ", 0)
+)
+
+// choice is a boolean flag that calls its func when given. Flags that
+// override one another, like -H, -L and -P, use it so that, as with GNU,
+// the last one given wins.
+type choice func()
+
+func (c choice) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if v {
+		c()
+	}
+	return err
+}
+
+func (choice) String() string   { return "false" }
+func (choice) IsBoolFlag() bool { return true }
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", HELP)
+		os.Exit(1)
+	}
+
+	flag.Var(choice(func() { preserveRoot = true }), "preserve-root", "fail recursive operation on '/'")
+	flag.Var(choice(func() { preserveRoot = false }), "no-preserve-root", "don't treat root '/' specially")
+	flag.VarP(choice(func() { traversal = chownlib.CommandLine }), "N1O1L1O1N1G1O1P1T1", "H", "if cli arg is sym link to dir, follow it")
+	flag.VarP(choice(func() { traversal = chownlib.Logical }), "N1O1L1O1N1G1O1P1T2", "L", "traverse every sym link")
+	flag.VarP(choice(func() { traversal = chownlib.Physical }), "N1O1L1O1N1G1O1P1T3", "P", "don't traverse any sym links")
+	flag.Parse()
+
+	if *help {
+		fmt.Printf("%s\n", HELP)
+		os.Exit(0)
+	}
+
+	if *version {
+		fmt.Printf("%s\n", Version)
+		os.Exit(0)
+	}
+
+	// Without --reference the first operand is GROUP.
+	need := 2
+	if *rfile != "" {
+		need = 1
+	}
+	if flag.NArg() < need {
+		if flag.NArg() == 0 {
+			fatal.Printf("missing operand\n")
+		} else {
+			fatal.Printf("missing operand after %s\n", quote.Name(flag.Arg(0)))
+		}
+		fatal.Fatalln("Try 'chgrp --help' for more information.")
+	}
+
+	opt := chownlib.NewOptions()
+	opt.Recursive = *recursive
+	opt.Dereference = *deref && !*noderef
+	opt.PreserveRoot = preserveRoot
+	opt.ForceSilent = *silent || *silent2
+	opt.Stdout = os.Stdout
+	opt.Stderr = fatal
+	if *verbose {
+		opt.Verbosity = chownlib.VHigh
+	} else if *changes {
+		opt.Verbosity = chownlib.VChangesOnly
+	}
+	opt.Traversal = traversal
+
+	// Like chown, -R defaults to -P, which never follows symbolic links,
+	// so asking to dereference them makes no sense without -H or -L.
+	if *recursive && opt.Traversal == chownlib.Physical {
+		if flag.Lookup("dereference").Changed {
+			fatal.Fatalln("-R --dereference requires either -H or -L")
+		}
+		opt.Dereference = false
+	}
+
+	// An empty GROUP leaves the group alone: opt.GID stays -1.
+	if *rfile != "" {
+		stat_t := unix.Stat_t{}
+		if err := unix.Stat(*rfile, &stat_t); err != nil {
+			fatal.Fatalf("failed to get attributes of %s: %s\n", quote.Name(*rfile), strerror.Text(err))
+		}
+		opt.GID = int(stat_t.Gid)
+		g := chownlib.GIDToName(stat_t.Gid)
+		opt.GroupName = &g
+	} else if group := flag.Arg(0); group != "" {
+		var err error
+		if opt.GID, _, err = chownlib.LookupGroup(group); err != nil {
+			fatal.Fatalf("invalid group: %s\n", quote.Name(group))
+		}
+		opt.GroupName = &group
+	}
+
+	if opt.Run(flag.Args()[need-1:]) != nil {
+		os.Exit(1)
+	}
+}
@@ -22,26 +22,23 @@
 	Inspired by GNU's chown-core (Extracted from chown.c/chgrp.c and librarified by Jim Meyering.)
 */
 
-// BUG(eric): -R flag could get stuck in an infinite loop
-
 package main
 
 import (
-	"errors"
 	"fmt"
-	flag "github.com/ogier/pflag"
+	"io"
 	"log"
 	"os"
 	"os/user"
-	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
-	"syscall"
-)
 
-type RCStatus int
-type CHStatus int
+	"github.com/EricLagerg/go-coreutils/chown/chownlib"
+	"github.com/EricLagerg/go-coreutils/internal/quote"
+	"github.com/EricLagerg/go-coreutils/internal/strerror"
+	flag "github.com/ogier/pflag"
+	"golang.org/x/sys/unix"
+)
 
 const (
 	HELP = `Usage: chown [OPTION]... [OWNER][:[GROUP]] FILE...
@@ -67,6 +64,13 @@ With --reference, change the owner and group of each FILE to those of RFILE.
       --reference=RFILE  use RFILE's owner and group rather than
                          specifying OWNER:GROUP values
   -R, --recursive        operate on files and directories recursively
+      --jobs=N           with -R, work on up to N directories at once
+      --dry-run          change nothing, but say what would be changed, as
+                         -c does (or -v or --report, if given)
+      --report=json      also describe each file processed as a JSON object,
+                         one per line, with its old and new owner and group
+      --report-file=FILE  write the --report output to FILE rather than
+                         standard output
 
 The following options modify how a hierarchy is traversed when the -R
 option is also specified.  If more than one is specified, only the final
@@ -90,7 +94,7 @@ Examples:
   chown root:staff /u  Likewise, but also change its group to "staff".
   chown -hR root /u    Change the owner of /u and subfiles to "root".
 
-Report wc bugs to ericscottlagergren@gmail.com
+Report chown bugs to ericscottlagergren@gmail.com
 Go coreutils home page: <https://www.github.com/EricLagerg/go-coreutils/>`
 	Version = `chown (Go coreutils) 1.0
 Copyright (C) 2014 Eric Lagergren
@@ -100,59 +104,6 @@ There is NO WARRANTY, to the extent permitted by law.
 
 Written by Eric Lagergren.
 Inspired by David MacKenzie and Jim Meyering.`
-
-	MAX_INT = int(^uint(0) >> 1)
-)
-
-// Copied from http://golang.org/src/pkg/os/types.go
-const (
-	// The single letters are the abbreviations
-	// used by the String method's formatting.
-	ModeDir        uint32 = 1 << (32 - 1 - iota) // d: is a directory
-	ModeAppend                                   // a: append-only
-	ModeExclusive                                // l: exclusive use
-	ModeTemporary                                // T: temporary file (not backed up)
-	ModeSymlink                                  // L: symbolic link
-	ModeDevice                                   // D: device file
-	ModeNamedPipe                                // p: named pipe (FIFO)
-	ModeSocket                                   // S: Unix domain socket
-	ModeSetuid                                   // u: setuid
-	ModeSetgid                                   // g: setgid
-	ModeCharDevice                               // c: Unix character device, when ModeDevice is set
-	ModeSticky                                   // t: sticky
-
-	// Mask for the type bits. For regular files, none will be set.
-	ModeType = ModeDir | ModeSymlink | ModeNamedPipe | ModeSocket | ModeDevice
-
-	ModePerm = 0777 // permission bits
-)
-
-const (
-	_  = iota // Don't need 0
-	__ // Don't need 1
-
-	// fchown succeeded
-	RCOk RCStatus
-
-	// uid/gid are specified and don't match
-	RCExcluded RCStatus
-
-	// SAME_INODE failed
-	RCInodeChanged RCStatus
-
-	// open/fchown isn't needed, safe, or doesn't work so use chown
-	RCDoOrdinaryCHown RCStatus
-
-	// open, fstat, fchown, or close failed
-	RCError RCStatus
-)
-
-const (
-	_                            = iota // Don't need 0
-	CHNotApplied        CHStatus
-	CHSucceeded         CHStatus
-	CHFailed            CHStatus
-	CHNoChangeRequested CHStatus
 )
 
 var (
@@ -160,522 +111,114 @@ var (
 	deref     = flag.Bool("dereference", true, "affect sym link referent")
 	noderef   = flag.BoolP("no-dereference", "h", false, "affect sym link rather than linked file")
 	from      = flag.String("from", "", "change owner and/or group if owner/group matches. Either may be omitted.")
-	npr       = flag.Bool("no-preserve-root", true, "don't treat root '/' specially")
-	pr        = flag.Bool("preserve-root", false, "fail recursive operation on '/")
 	silent    = flag.BoolP("silent", "f", false, "suppress most error messages")
 	silent2   = flag.Bool("quiet", false, "suppress most error messages")
 	rfile     = flag.String("reference", "", "use RFILE's owner/group")
 	recursive = flag.BoolP("recursive", "R", false, "operate recursively")
 	verbose   = flag.BoolP("verbose", "v", false, "diagnostic for each file")
-	travDir   = flag.BoolP("N1O1L1O1N1G1O1P1T1", "H", false, "if cli arg is sym link to dir, follow it")
-	travAll   = flag.BoolP("N1O1L1O1N1G1O1P1T2", "L", false, "traverse every sym link")
-	noTrav    = flag.BoolP("N1O1L1O1N1G1O1P1T3", "P", true, "don't traverse any sym links")
+	jobs      = flag.Int("jobs", 1, "change up to N trees at once with -R")
+	dryRun    = flag.Bool("dry-run", false, "say what would change, but don't")
+	report    = flag.String("report", "", "describe each file in FORMAT")
+	reportTo  = flag.String("report-file", "", "write the report to FILE")
+	help      = flag.Bool("help", false, "print help")
 	version   = flag.Bool("version", false, "print program's version\n")
 
-	optUid = -1 // Specified uid; -1 if not to be changed.
-	optGid = -1 // Specified gid; -1 if not to be changed.
-
-	// Change the owner (group) of a file only if it has this uid (gid).
-	// -1 means there's no restriction.
-	reqUid = -1
-	reqGid = -1
-
-	mute = *silent || *silent2
+	// Set by -H, -L and -P, and by --[no-]preserve-root, which override
+	// each other.
+	traversal    = chownlib.Physical
+	preserveRoot = false
 
-	DoNotFollow = false
-	// I used to try to curb infine symlink loops when -R was used, but
-	// as it turns out Go just loops a lot and then quits for some reason
-	// since this does, essentially, the same thing as testing for infinite
-	// looping, I'm gonna leave it for right now.
-	// I mean, it hasn't hung my system... yet.
-	//anchor        = make(map[uint64]bool)
-
-	RootInode uint64
-
-	SkipDir  = errors.New("skip this directory")
-	CantFind = errors.New("can't find user/group/uid/gid")
+	fatal = log.New(os.Stderr, "chown: ", 0)
 )
 
-func nameToUid(name string) (int, error) {
-	u, err := user.Lookup(name)
-	if err != nil {
-		return -2, CantFind
-	}
-	i, _ := strconv.Atoi(u.Uid)
-	return i, nil
-}
-
-func nameToGid(name string) (int, error) {
-	g, err := user.LookupGroup(name)
-	if err != nil {
-		return -2, CantFind
-	}
-	i, _ := strconv.Atoi(g.Gid)
-	return i, nil
-}
-
-func gidToName(gid uint32) (string, error) {
-	g, err := user.LookupGroupId(strconv.FormatUint(uint64(gid), 10))
-	if err != nil {
-		return "", CantFind
-	}
-	return g.Name, nil
-}
-
-func uidToName(uid uint32) (string, error) {
-	u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10))
-	if err != nil {
-		return "", CantFind
-	}
-	return u.Username, nil
-}
+// choice is a boolean flag that calls its func when given. Flags that
+// override one another, like -H, -L and -P, use it so that, as with GNU,
+// the last one given wins.
+type choice func()
 
-func UserGroupStr(user, group string) string {
-	var spec string
-	if user != "" {
-		if group != "" {
-			spec = fmt.Sprintf("%s:%s", user, group)
-		} else {
-			spec = fmt.Sprintf("%s", user)
-		}
-	} else if group != "" {
-		spec = fmt.Sprintf("%s", group)
+func (c choice) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if v {
+		c()
 	}
-	return spec
+	return err
 }
 
-func walk(path string, info os.FileInfo, uid, gid, reqUid, reqGid int) bool {
-	var ok bool
-	var fileInfo os.FileInfo
-	var err error
-	var fileIsSym bool
-	followSym := false
-
-	stat_t := syscall.Stat_t{}
-	err = syscall.Stat(path, &stat_t)
-
-	/*if _, ok := anchor[stat_t.Ino]; ok {
-		fmt.Print("we've found a loop! Now exiting because loops are bad.\n")
-		os.Exit(1)
-	}*/
+func (choice) String() string   { return "false" }
+func (choice) IsBoolFlag() bool { return true }
 
-	if info.Mode()&os.ModeSymlink == os.ModeSymlink {
-		fileIsSym = true
-	}
-
-	if DoNotFollow {
-		return false
+// DetermineInput looks up a user (or group) name or number. An empty
+// name is -1, meaning unchanged. The name is returned for -v, or "" if
+// it was a number, which -v shows in decimal.
+func DetermineInput(input string, user bool) (int, string, error) {
+	if input == "" {
+		return -1, "", nil
 	}
-
-	ok = ChangeOwner(path, info, uid, gid, reqUid, reqGid)
-
-	names, err := readDirNames(path)
-	if err != nil {
-		return false
+	lookup := chownlib.LookupGroup
+	if user {
+		lookup = chownlib.LookupUser
 	}
-
-	for _, name := range names {
-		filename := filepath.Join(path, name)
-		if !*deref {
-			fileInfo, err = os.Lstat(filename)
-			if fileIsSym {
-				followSym = true
-			}
-		} else {
-			fileInfo, err = os.Stat(filename)
-		}
-
-		if err != nil {
-			ok = false
-		} else {
-
-			// If we're going to chown the symlink instead of the linked file,
-			// we need to do it before we follow the link and continue with
-			// our recursive chowning
-			if followSym && *travAll || *travDir { //&& fileInfo.IsDir() {
-				_ = ChangeOwner(filename, fileInfo, uid, gid, reqUid, reqGid)
-				filename, _ = os.Readlink(filename)
-				ok = walk(filename, fileInfo, uid, gid, reqUid, reqGid)
-			} else if fileInfo.IsDir() {
-				ok = walk(filename, fileInfo, uid, gid, reqUid, reqGid)
-			} else {
-				ok = ChangeOwner(filename, fileInfo, uid, gid, reqUid, reqGid)
-			}
-		}
+	id, numeric, err := lookup(input)
+	if numeric {
+		input = ""
 	}
-	return ok
+	return id, input, err
 }
 
-// from http://golang.org/src/pkg/path/filepath/path.go
-func readDirNames(dirname string) ([]string, error) {
-	f, err := os.Open(dirname)
-	if err != nil {
-		return nil, err
+// parseSpec splits an OWNER[:GROUP] spec, as given to chown or --from,
+// and looks up each part. An omitted part is -1, except that a symbolic
+// OWNER followed by a bare ':' means that user's login group. It also
+// returns the names -v should use, nil for an omitted or numeric part.
+func parseSpec(spec string) (uid, gid int, owner, group *string) {
+	i := strings.IndexByte(spec, ':')
+	if i < 0 {
+		uid, owner := lookupOwner(spec, spec)
+		return uid, -1, name(owner), nil
 	}
-	names, err := f.Readdirnames(-1)
-	f.Close()
-	if err != nil {
-		return nil, err
-	}
-	sort.Strings(names)
-	return names, nil
-}
 
-// Returns true if chown is successful on all files
-func ChownFiles(fname string, uid, gid, reqUid, reqGid int) bool {
-	ok := false
-
-	if !*deref {
-		fi, err := os.Lstat(fname)
+	ownerName, groupName := spec[:i], spec[i+1:]
+	if ownerName != "" && groupName == "" {
+		u, err := user.Lookup(ownerName)
 		if err != nil {
-			if !mute {
-				fmt.Printf("cannot lstat() file or directory '%s'\n", fname)
-			}
+			fatal.Fatalf("invalid spec: %s\n", quote.Name(spec))
 		}
-		if *recursive && fi.Mode()&os.ModeSymlink != os.ModeSymlink {
-			if walk(fname, fi, uid, gid, reqUid, reqGid) {
-				ok = true
-			}
-		} else {
-			if ChangeOwner(fname, fi, uid, gid, reqUid, reqGid) {
-				ok = true
-			}
-		}
-	} else {
-		fi, err := os.Stat(fname)
-		if err != nil {
-			if !mute {
-				fmt.Printf("cannot stat() file or directory '%s'\n", fname)
-			}
-		}
-		if *recursive {
-			if walk(fname, fi, uid, gid, reqUid, reqGid) {
-				ok = true
-			}
-		} else {
-			if ChangeOwner(fname, fi, uid, gid, reqUid, reqGid) {
-				ok = true
-			}
-		}
-	}
-
-	return ok
-}
-
-func ChangeOwner(fname string, origStat os.FileInfo, uid, gid, reqUid, reqGid int) bool {
-	var status RCStatus
-	var doChown bool
-	var changed bool
-	var changeStatus CHStatus
-
-	symlinkChanged := true
-	ok := true
-
-	fi, err := os.Open(fname)
-	if err != nil {
-		if !mute {
-			fmt.Printf("%v %s\n", err, fname)
-		}
-		ok = false
+		uid, _ = strconv.Atoi(u.Uid)
+		gid, _ = strconv.Atoi(u.Gid)
+		login := chownlib.GIDToName(uint32(gid))
+		return uid, gid, &ownerName, &login
 	}
 
-	stat_t := syscall.Stat_t{}
-	err = syscall.Stat(fname, &stat_t)
-
-	// TODO: Better error messages, similar to fts(3)'s FTS_DNR, FTS_ERR,
-	// and so on
+	uid, ownerName = lookupOwner(ownerName, spec)
+	gid, groupName, err := DetermineInput(groupName, false)
 	if err != nil {
-		if !mute {
-			fmt.Printf("%v %s\n", err, fname)
-		}
-		ok = false
-	}
-
-	// Check if we've stumbled across a directory
-	if stat_t.Mode&ModeDir != 0 && stat_t.Ino == RootInode {
-		if *recursive && *pr {
-			fmt.Print("cannot run chown on root directory (--preserve-root specified\n")
-			DoNotFollow = true
-			return false
-		} else {
-			// Regardless of whether or not -f is true, print the warning
-			// because I figure it won't bug anybody and it's better to
-			// let people know they're about to do something bad than
-			// have them do it without knowing!
-			fmt.Println("warning: running chown on root directory without protection")
-		}
-	}
-
-	if !ok {
-		doChown = false
-	} else {
-		doChown = true
-	} /*else if reqUid == -1 && reqGid == -1 && !*verbose && !*deref {
-		doChown = true
-	}*/
-
-	if doChown {
-		if !*deref {
-			err := os.Lchown(fname, uid, gid)
-
-			// GNU's chown says it ignores any error due to lack of support.
-			// Apparently "POSIX requires this behavior for any top-level sym
-			// links with -h, and implies it's required for all symlinks."
-			if e, k := err.(*os.PathError); k && e.Err == syscall.EOPNOTSUPP {
-				ok = true
-				symlinkChanged = false
-			} else if e, k := err.(*os.PathError); k && e.Err == syscall.EPERM || e.Err == syscall.EACCES {
-				if !mute {
-					fmt.Printf("%s\n", err)
-				}
-				ok = false
-			} else if err != nil {
-				ok = true
-			} else {
-				ok = false
-			}
-		} else {
-			// Double check the size of the fd because the Go's openat() wants
-			// the cwd_fd to be of the int type, while os.File's Fd() returns
-			// a uintptr() of the fd. Theoretically, this means that the value
-			// returned from Fd() could be out of the bounds of our int, thus
-			// causing us to accidentally chown incorrectly.
-			//
-			// os.File.Fd() is the only way that I know how to get the fd without
-			// actually using openat() -- which we can't do without a fd
-			//
-			// Apparently there's a soft restriction of ~4 billion inodes
-			// which is set when the filesystem is created
-			// Since int in Go is >= 32 bits, we have a range of
-			// -2147483648 through 2147483647, which means we have about 1.8
-			// billion inodes that we cannot (assuming 32 bit int size) run
-			// RestrictedChown() on. That said, I'd be willing to bet most
-			// systems do not have > 2147483647 inodes. If a system does,
-			// we'll have to hope that said system is using 64 bit ints.
-			// (Which is becoming more and more common.)
-			if fi.Fd() <= uintptr(MAX_INT) {
-				status = RestrictedChown(int(fi.Fd()), fname, origStat, uid, gid, reqUid, reqGid)
-			} else {
-				log.Fatalln("Go sucks, use C (just kidding)")
-			}
-
-			switch status {
-			case RCOk:
-				break
-			case RCDoOrdinaryCHown:
-				if !*deref {
-					err := os.Lchown(fname, uid, gid)
-					if err != nil {
-						ok = false
-						if os.IsPermission(err) {
-							if !mute {
-								fmt.Printf("%s\n", err)
-							}
-						}
-					} else {
-						ok = true
-					}
-				} else {
-					err := os.Chown(fname, uid, gid)
-					if err != nil {
-						ok = false
-						if os.IsPermission(err) {
-							if !mute {
-								fmt.Printf("%s\n", err)
-							}
-						}
-					} else {
-						ok = true
-					}
-				}
-			case RCError:= false
-			case RCInodeChanged:
-				fmt.Printf("inode changed during chown of '%s'\n", fname)
-			case RCExcluded:
-				doChown = false
-				ok = false
-		
-				log.Fatalln("Now how did this happen?")
-			}
-	}
-
-	if *verbose || *changes && !mute {
-		if changed = doChown && ok && symlinkChanged &&
-			!((uid == -1 || uint32(uid) == stat_t.Uid) &&
-				(gid == -1 || int32(gid) == stat_t.Gid)); changed || *verbose {
-
-			if !ok {
-				changeStatus = CHFailed
-			} else if !symlinkChanged {
-				changeStatus
-			} else if true == true {
-				changeStatus
-			} 
-				changeStatus = CHSucceeded
-			}
-
-			oldUsr, _ := uidToName(stat_t.Uid)
-			oldGroup, _ := gidToName(stat_t.Gid)
-			newUsr, _ := uidToName(uint32(optUid))
-			newGrp, _ := gidToName(uint32(optGid))
-
-			DescribeChange(fname, changeStatus, oldUsr, oldGroup, newUsr, newGrp)
-		}
-	}
-	return ok
-}
-
-func RestrictedChown(cwd_fd int, file string, origStat os.FileInfo, uid, gid, reqUid, reqGid int) RCStatus {
-	var status RCStatus
-
-	openFlags := syscall.O_NONBLOCK | syscall.O_NOCTTY
-
-	fstat := syscall.Stat_t{}
-	err := syscall.Stat(file, &fstat)
-
-	fileInfo, err := os.Stat(file)
-	fileMode := fileInfo.Mode()
-
-	if reqUid == -1 && reqGid == -1 {
-		return RCDoOrdinaryCHown
-	}
-
-	if !fileMode.IsRegular() {
-		if fileMode.IsDir() {
-			openFlags |= syscall.O_DIRECTORY
-		} else {
-			return RCDoOrdinaryCHown
-		}
-	}
-
-	fd, errno := syscall.Openat(cwd_fd, file, syscall.O_RDONLY|openFlags, 0)
-
-	if !(0 <= fd || errno != nil && fileMode.IsRegular()) {
-		if fd, err = syscall.Openat(cwd_fd, file, syscall.O_WRONLY|openFlags, 0); !(0 <= fd) {
-			if err == syscall.EACCES {
-				return RCDoOrdinaryCHown
-			} else {
-				return RCError
-			}
-		}
+		fatal.Fatalf("invalid group: %s\n", quote.Name(spec))
 	}
 
-	if err := syscall.Fstat(fd, &fstat); err != nil {
-		status = RCError
-	} else if !os.SameFile(origStat, fileInfo) {
-		status = RCInodeChanged
-	} else if (reqUid == -1 || uint32(reqUid) == fstat.Uid) && (reqGid == -1 || uint32(reqGid) == fstat.Gid) { // Sneaky chown lol
-		if err := syscall.Fchown(fd, uid, gid); err == nil {
-			if err := syscall.Close(fd); err == nil {
-				return RCOk
-			} else {
-				return RCError
-			}
-		} else {
-			if os.IsPermission(err) {
-				fmt.Printf("%s\n", err)
-			}
-			status = RCError
-		}
+	// Like GNU, a named group without a named owner is still described
+	// as a change of ownership, to ":GROUP".
+	owner, group = name(ownerName), name(groupName)
+	if owner == nil && group != nil {
+		owner = new(string)
 	}
-	err = syscall.Close(fd)
-	if= nil {
-		log.Fatalln(err)
-	}
-
+	return uid, gid, owner, group
 }
 
-func DescribeChange(file string, changed CHStatus, olduser, oldgroup, user, group strin
-
-	userbool := false
-	groupbool
-
-	if user != "" {
-		userbool
-	} else {
-		olduser = ""
-	if group != "" {
-		groupbool = true
-	} else {
-		oldgroup = ""
-	}
-
-	if changed =
-		fmt.Printf("neither symbolic link '%s' nor referent has\n", file
-	spec := UserGroupStr(user, group)
-	oldspec := UserGroupStr(olduser, oldgroup)
-
-	switch changed {
-	case CHSucceeded:
-		if userbool {
-			fmt.Printf("changed ownership of '%s' from %s to %s\n", file, oldspec, spec)
-		} else if groupbool {
-			fmt.Printf("changed group of '%s' from %s to %s\n", file, oldspec, spec)
-		} else {
-			fmt.Printf("no change in ownership of %s\n", file)
-		}
-	case CHFailed:
-		if oldspec != "" {
-			if userbool {
-				fmt.Printf("failed to change ownership of '%s' from %s to %s\n", file, oldspec, spec)
-			} else if groupbool {
-				fmt.Printf("failed to change group of '%s' from %s to %s\n", file, oldspec, spec)
-			} else {
-				fmt.Printf("failed to change ownership of %s\n", file)
-			}
-		} else {
-			if userbool {
-				fmt.Printf("failed to change ownership of '%s' from %s to %s\n", file, oldspec, spec)
-			} else if groupbool {
-				fmt.Printf("failed to change group of '%s' from %s to %s\n", file, oldspec, spec)
-			} else {
-				fmt.Printf("failed to change ownership of %s\n", file)
-			}
-			oldspec = spec
-		}
-	case CHNoChangeRequested:
-		if userbool {
-			fmt.Printf("ownership of '%s' retained as %s\n", file, oldspec)
-		} else if groupbool {
-			fmt.Printf("ownership of '%s' retained as %s\n", file, oldspec)
-		} else {
-			fmt.Printf("ownership of '%s' retained\n", file)
-		}
-	default:
-		log.Fatalln("let's go out with a bang!") // TODO: Good error messages lol
+// lookupOwner returns the uid for owner, the OWNER part of spec, and its
+// name for -v, exiting if it's neither a user nor a number.
+func lookupOwner(owner, spec string) (int, string) {
+	uid, name, err := DetermineInput(owner, true)
+	if err != nil {
+		fatal.Fatalf("invalid user: %s\n", quote.Name(spec))
 	}
+	return uid, name
 }
 
-func DetermineInput(input string, user bool) int {
-	if input == "" {
-		return -1
-	} else if user {
-		if id, err := nameToUid(input); err == nil {
-			return id
-		} else {
-			if _, err = uidToName(uint32(id)); err == nil {
-				return id
-			}
-		}
-	} else if !user {
-		if id, err := nameToGid(input); err == nil {
-			return id
-		} else {
-			if _, err = uidToName(uint32(id)); err == nil {
-				return id
-			}
-		}
-	} else {
-		// Basically if the user/uid/group/gid aren't found *and* the input
-		// isn't -1, error out.
-		if user {
-			fmt.Fprintf(os.Stderr, "invalid username/uid %s\n", input)
-		} else {
-			fmt.Fprintf(os.Stderr, "invalid groupame/gid %s\n", input)
-		}
-		os.Exit(1)
+// name returns a pointer to s, or nil if s is empty.
+func name(s string) *string {
+	if s == "" {
+		return nil
 	}
-	log.Fatalln("We shouldn't be here right now.")
+	return &s
 }
 
 // We have to do extra arg parsing here because chown doesn't use the
@@ -691,17 +234,26 @@ func DetermineInput(input string, user bool) int {
 // string(s) (e.g. eric:root -> args[0] == eric && args[1] == root)
 func main() {
 	shopts := false // Short opts if *rfile
-	ok := false
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "%s\n", HELP)
-		os.Exit(0)
+		os.Exit(1)
 	}
 
+	flag.Var(choice(func() { preserveRoot = true }), "preserve-root", "fail recursive operation on '/'")
+	flag.Var(choice(func() { preserveRoot = false }), "no-preserve-root", "don't treat root '/' specially")
+	flag.VarP(choice(func() { traversal = chownlib.CommandLine }), "N1O1L1O1N1G1O1P1T1", "H", "if cli arg is sym link to dir, follow it")
+	flag.VarP(choice(func() { traversal = chownlib.Logical }), "N1O1L1O1N1G1O1P1T2", "L", "traverse every sym link")
+	flag.VarP(choice(func() { traversal = chownlib.Physical }), "N1O1L1O1N1G1O1P1T3", "P", "don't traverse any sym links")
 	flag.Parse()
 
+	if *help {
+		fmt.Printf("%s\n", HELP)
+		os.Exit(0)
+	}
+
 	if *version {
-		fmt.Fprintf(os.Stderr, "%s\n", Version)
+		fmt.Printf("%s\n", Version)
 		os.Exit(0)
 	}
 
@@ -709,58 +261,101 @@ func main() {
 		shopts = true
 	}
 
-	if flag.NArg() < 2 || shopts && flag.NArg() < 1 {
+	// Without --reference the first operand is OWNER[:GROUP].
+	need := 2
+	if shopts {
+		need = 1
+	}
+	if flag.NArg() < need {
 		if flag.NArg() == 0 {
-			fmt.Print("chown: missing operand\n")
+			fatal.Printf("missing operand\n")
 		} else {
-			fmt.Printf("chown: missing operand after '%s'\n", flag.Arg(0))
+			fatal.Printf("missing operand after %s\n", quote.Name(flag.Arg(0)))
 		}
-		log.Fatalln("Try 'chown --help' for more information")
+		fatal.Fatalln("Try 'chown --help' for more information.")
 	}
 
-	if *recursive && *deref && !*travAll && *noTrav {
-		log.Fatalln("-R --dereference requires either -H or -L")
+	// Like GNU, -R defaults to -P, which never follows symbolic links, so
+	// asking to dereference them makes no sense without -H or -L.
+	if *recursive && traversal == chownlib.Physical {
+		if flag.Lookup("dereference").Changed {
+			fatal.Fatalln("-R --dereference requires either -H or -L")
+		}
+		*deref = false
 	}
 
+	opt := chownlib.NewOptions()
+	opt.Recursive = *recursive
+	opt.Dereference = *deref && !*noderef
+	opt.PreserveRoot = preserveRoot
+	opt.ForceSilent = *silent || *silent2
+	opt.Jobs = *jobs
+	opt.DryRun = *dryRun
+	opt.Stdout = os.Stdout
+	opt.Stderr = fatal
+	if *verbose {
+		opt.Verbosity = chownlib.VHigh
+	} else if *changes || *dryRun && *report == "" {
+		opt.Verbosity = chownlib.VChangesOnly
+	}
+	opt.Traversal = traversal
+
 	if shopts {
-		stat_t := syscall.Stat_t{}
-		err := syscall.Stat(*rfile, &stat_t)
+		stat_t := unix.Stat_t{}
+		err := unix.Stat(*rfile, &stat_t)
 		if err != nil {
-			log.Fatalf("failed to get attributes of '%s'\n", *rfile)
+			fatal.Fatalf("failed to get attributes of %s: %s\n", quote.Name(*rfile), strerror.Text(err))
 		}
-		optUid = int(stat_t.Uid)
-		optGid = int(stat_t.Gid)
+		opt.UID = int(stat_t.Uid)
+		opt.GID = int(stat_t.Gid)
+		u, g := chownlib.UIDToName(stat_t.Uid), chownlib.GIDToName(stat_t.Gid)
+		opt.UserName, opt.GroupName = &u, &g
 	} else {
-		idArr := strings.Split(flag.Args()[0], ":")
-		optUid = DetermineInput(idArr[0], true)
-		optGid = DetermineInput(idArr[1], false)
+		opt.UID, opt.GID, opt.UserName, opt.GroupName = parseSpec(flag.Arg(0))
 	}
 
 	if *from != "" {
-		idArr := strings.Split(*from, ":")
-		reqUid = DetermineInput(idArr[0], true)
-		reqGid = DetermineInput(idArr[1], false)
+		opt.FromUID, opt.FromGID, _, _ = parseSpec(*from)
 	}
 
-	if *recursive && *pr {
-		stat_t := syscall.Stat_t{}
-		if err := syscall.Stat("/", &stat_t); err != nil {
-			log.Fatalf("failed to get attributes of %q\n", "/")
-		}
-		RootInode = stat_t.Ino
+	if *jobs < 1 {
+		fatal.Printf("invalid number of jobs: %s\n", quote.Name(strconv.Itoa(*jobs)))
+		fatal.Fatalln("Try 'chown --help' for more information.")
 	}
 
-	if shopts {
-		for _, file := range flag.Args()[2:] {
-			ok = ChownFiles(file, optUid, optGid, reqUid, reqGid)
+	var reportFile *os.File
+	switch *report {
+	case "":
+		if *reportTo != "" {
+			fatal.Printf("--report-file requires --report\n")
+			fatal.Fatalln("Try 'chown --help' for more information.")
 		}
-	} else {
-		for _, file := range flag.Args()[1:] {
-			ok = ChownFiles(file, optUid, optGid, reqUid, reqGid)
+	case "json":
+		var w io.Writer = os.Stdout
+		if *reportTo != "" {
+			var err error
+			if reportFile, err = os.Create(*reportTo); err != nil {
+				fatal.Fatalf("cannot open %s for writing: %s\n", quote.Name(*reportTo), strerror.Text(err))
+			}
+			w = reportFile
 		}
+		opt.Report = func(ch chownlib.Change) { writeRecord(w, ch) }
+	default:
+		fatal.Printf("invalid argument %s for '--report'\n", quote.Name(*report))
+		fmt.Fprintf(os.Stderr, "Valid arguments are:\n  - 'json'\n")
+		fatal.Fatalln("Try 'chown --help' for more information.")
 	}
 
-	if !ok {
+	// Keep going after a failure, like GNU, but remember it for the
+	// exit status.
+	err := opt.Run(flag.Args()[need-1:])
+
+	if reportFile != nil {
+		if err := reportFile.Close(); err != nil {
+			fatal.Fatalf("%s: %s\n", quote.Name(*reportTo), strerror.Text(err))
+		}
+	}
+	if err != nil {
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,809 @@
+/*
+	Go chownlib -- change the ownership of files
+
+	Copyright (C) 2014 Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+	Written by Eric Lagergren <ericscottlagergren@gmail.com>
+	Inspired by GNU's chown-core (Extracted from chown.c/chgrp.c and librarified by Jim Meyering.)
+*/
+
+// BUG(eric): -L could get stuck in an infinite loop
+
+// Package chownlib changes the ownership of files and file hierarchies
+// the way chown and chgrp do, for programs that want to without running
+// them. For instance,
+//
+//	opt := chownlib.NewOptions()
+//	opt.UID = 1000
+//	opt.Recursive = true
+//	err := opt.Run([]string{"/srv/www"})
+//
+// changes the owner of /srv/www and everything in it to uid 1000.
+package chownlib
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"os/user"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EricLagerg/go-coreutils/internal/quote"
+	"github.com/EricLagerg/go-coreutils/internal/strerror"
+	"golang.org/x/sys/unix"
+)
+
+// Verbosity says what is written to Options.Stdout.
+type Verbosity int
+
+const (
+	VOff         Verbosity = iota // nothing
+	VChangesOnly                  // -c: each file that was changed
+	VHigh                         // -v: every file
+)
+
+// Traversal says which symbolic links to directories a recursive walk
+// follows.
+type Traversal int
+
+const (
+	Physical    Traversal = iota // -P: none
+	CommandLine                  // -H: those named in Run's paths
+	Logical                      // -L: all
+)
+
+// Status says what happened to a file.
+type Status int
+
+const (
+	Changed     Status = iota // its ownership was changed
+	WouldChange               // it would have been, but for DryRun
+	Retained                  // it already had it, or didn't match From
+	NotApplied                // it's a symbolic link that can't be changed
+	Failed                    // see Change.Err
+)
+
+var statusNames = [...]string{
+	Changed:     "changed",
+	WouldChange: "would-change",
+	Retained:    "retained",
+	NotApplied:  "not-applied",
+	Failed:      "failed",
+}
+
+func (s Status) String() string {
+	return statusNames[s]
+}
+
+// A Change describes what Run did with one file.
+type Change struct {
+	Path   string
+	Status Status
+
+	// The file's owner and group before and after, or -1 if it couldn't
+	// be looked at.
+	OldUID, OldGID int
+	NewUID, NewGID int
+
+	// Err is why the file wasn't changed, if it Failed.
+	Err error
+}
+
+// Options say what Run changes and how.
+type Options struct {
+	// UID and GID are the new owner and group. -1 leaves either alone.
+	UID, GID int
+
+	// FromUID and FromGID, unless -1, restrict the changes to files
+	// owned by that user and group, like chown's --from.
+	FromUID, FromGID int
+
+	// Recursive walks into directories, following symbolic links to them
+	// as Traversal says.
+	Recursive bool
+	Traversal Traversal
+
+	// Dereference changes what a symbolic link points to rather than
+	// the link itself.
+	Dereference bool
+
+	// PreserveRoot refuses to walk "/", under any name.
+	PreserveRoot bool
+
+	// DryRun does everything but change the files, so that Report and
+	// the -c and -v output say what would have been changed.
+	DryRun bool
+
+	// Jobs is how many directories a recursive walk may work on at once.
+	// Less than 1 means 1.
+	Jobs int
+
+	// Report, if not nil, is called for each file processed. The calls
+	// come from Run's goroutine, in the same order whatever Jobs is.
+	Report func(Change)
+
+	// The rest are for chown's and chgrp's own output.
+
+	Verbosity Verbosity
+
+	// ForceSilent suppresses most diagnostics.
+	ForceSilent bool
+
+	// UserName and GroupName describe the new owner and group in -v
+	// output, as the user gave them. If nil, the UID or GID is shown
+	// instead, unless it's -1.
+	UserName, GroupName *string
+
+	// Stdout receives the -c and -v output and Stderr the diagnostics.
+	// Nil discards them.
+	Stdout io.Writer
+	Stderr *log.Logger
+}
+
+// NewOptions returns the Options chown starts with: nothing to change,
+// no restriction on what is changed, and symbolic links dereferenced.
+func NewOptions() *Options {
+	return &Options{
+		UID:         -1,
+		GID:         -1,
+		FromUID:     -1,
+		FromGID:     -1,
+		Dereference: true,
+		Jobs:        1,
+	}
+}
+
+type rcStatus int
+
+const (
+	// fchown succeeded
+	rcOK rcStatus = iota + 2
+
+	// uid/gid are specified and don't match
+	rcExcluded
+
+	// SAME_INODE failed
+	rcInodeChanged
+
+	// open/fchown isn't needed, safe, or doesn't work so use chown
+	rcDoOrdinaryChown
+
+	// open, fstat, fchown, or close failed
+	rcError
+)
+
+var (
+	// errReplaced is the error for a directory that isn't the one we
+	// stat'd, and errInodeChanged for any other file.
+	errReplaced     = errors.New("directory was replaced during the walk")
+	errInodeChanged = errors.New("file was replaced before it could be changed")
+
+	errRoot = errors.New("refusing to operate recursively on '/'")
+)
+
+// A chowner is one call to Run.
+type chowner struct {
+	*Options
+
+	// Device and inode of "/" when PreserveRoot is set. Comparing both is
+	// the only way to recognize it: an inode number alone is only unique
+	// within a filesystem.
+	rootDev, rootIno uint64
+
+	// workers has room for each goroutine Jobs allows besides the
+	// caller's.
+	workers chan struct{}
+
+	err error // the first failure
+}
+
+// Run changes the ownership of each of paths as opt says. Like chown, it
+// carries on past a file it can't change, and returns the first such
+// failure as an *os.PathError once it's done.
+func (opt *Options) Run(paths []string) error {
+	c := &chowner{Options: opt}
+	if c.Jobs > 1 {
+		c.workers = make(chan struct{}, c.Jobs-1)
+	}
+
+	r := &report{out: c.Stdout, diag: c.Stderr}
+	if r.out == nil {
+		r.out = ioutil.Discard
+	}
+	if r.diag == nil {
+		r.diag = log.New(ioutil.Discard, "", 0)
+	}
+	r.emit = func(ch Change) {
+		if ch.Status == Failed && c.err == nil {
+			c.err = &os.PathError{Op: "chown", Path: ch.Path, Err: ch.Err}
+		}
+		if c.Report != nil {
+			c.Report(ch)
+		}
+	}
+
+	if c.Recursive && c.PreserveRoot {
+		st := unix.Stat_t{}
+		if err := unix.Stat("/", &st); err != nil {
+			r.diag.Printf("failed to get attributes of '/': %s\n", strerror.Text(err))
+			return &os.PathError{Op: "stat", Path: "/", Err: err}
+		}
+		c.rootDev, c.rootIno = uint64(st.Dev), uint64(st.Ino)
+	}
+
+	for _, path := range paths {
+		c.chownFile(r, path)
+	}
+	return c.err
+}
+
+// chownFile changes the ownership of fname, one of Run's paths.
+func (c *chowner) chownFile(r *report, fname string) bool {
+	// Only -H and -L look through a symbolic link to decide whether to
+	// walk; otherwise changeOwner resolves it if it has to.
+	flags := unix.AT_SYMLINK_NOFOLLOW
+	if c.Recursive && c.Traversal != Physical {
+		flags = 0
+	}
+
+	st := unix.Stat_t{}
+	if err := statAt(unix.AT_FDCWD, fname, &st, flags); err != nil {
+		if !c.ForceSilent {
+			r.diag.Printf("cannot access %s: %s\n", quote.Name(fname), strerror.Text(err))
+		}
+		c.reportFailure(r, fname, nil, err)
+		return false
+	}
+
+	if c.Recursive && st.Mode&unix.S_IFMT != unix.S_IFLNK {
+		return c.walk(r, unix.AT_FDCWD, fname, fname, &st)
+	}
+	return c.changeOwner(r, unix.AT_FDCWD, fname, fname, &st)
+}
+
+// walk changes the ownership of name, relative to the directory dirfd,
+// and, if it's a directory, of everything beneath it. path is its name
+// for messages and st is what it was when we looked. A file that can't be
+// read or changed is reported and skipped rather than ending the walk.
+// Returns true if every file was changed.
+//
+// Every directory is held open while its entries are stat'd and changed
+// relative to it, so renaming or replacing a directory above with a
+// symbolic link partway through can't send the walk somewhere else.
+func (c *chowner) walk(r *report, dirfd int, name, path string, st *unix.Stat_t) bool {
+	if st.Mode&unix.S_IFMT != unix.S_IFDIR {
+		return c.changeOwner(r, dirfd, name, path, st)
+	}
+
+	if c.isRoot(st) {
+		if path == "/" {
+			r.diag.Printf("it is dangerous to operate recursively on '/'\n")
+		} else {
+			r.diag.Printf("it is dangerous to operate recursively on %s (same as '/')\n", quote.Name(path))
+		}
+		r.diag.Printf("use --no-preserve-root to override this failsafe\n")
+		r.emit(Change{
+			Path:   path,
+			Status: Failed,
+			OldUID: int(st.Uid),
+			OldGID: int(st.Gid),
+			NewUID: int(st.Uid),
+			NewGID: int(st.Gid),
+			Err:    errRoot,
+		})
+		return false
+	}
+
+	// Only a command line argument (the only thing walked relative to
+	// the working directory) is followed with -H.
+	follow := c.Traversal == Logical ||
+		c.Traversal == CommandLine && dirfd == unix.AT_FDCWD
+
+	// Like GNU, leave a directory we can't read alone, and change the
+	// others only after their contents, so that taking away our own
+	// access can't cut the walk short.
+	fd, names, err := openDir(dirfd, name, st, follow)
+	if err != nil {
+		if !c.ForceSilent {
+			r.diag.Printf("cannot read directory %s: %s\n", quote.Name(path), strerror.Text(err))
+		}
+		c.reportFailure(r, path, st, err)
+		return false
+	}
+
+	// Only -L follows symbolic links below the command line.
+	statFlags := unix.AT_SYMLINK_NOFOLLOW
+	if c.Traversal == Logical {
+		statFlags = 0
+	}
+
+	ok := true
+	var queue []*segment // children's output that isn't written yet
+	for _, child := range names {
+		childPath := path + "/" + child
+		if strings.HasSuffix(path, "/") {
+			childPath = path + child
+		}
+
+		var childSt unix.Stat_t
+		err := statAt(fd, child, &childSt, statFlags)
+
+		// With Jobs, a subdirectory goes to another goroutine if one is
+		// free. It and every later sibling write into segments, which
+		// are copied out in order so the output is the same as without.
+		if err == nil && childSt.Mode&unix.S_IFMT == unix.S_IFDIR {
+			seg := newSegment(r)
+			if c.tryGo(func() {
+				seg.finish(c.walk(&seg.report, fd, child, childPath, &childSt))
+			}) {
+				queue = append(queue, seg)
+				continue
+			}
+		}
+
+		cur := r
+		var seg *segment
+		if len(queue) > 0 {
+			seg = newSegment(r)
+			cur = &seg.report
+		}
+
+		childOK := false
+		if err != nil {
+			if !c.ForceSilent {
+				cur.diag.Printf("cannot access %s: %s\n", quote.Name(childPath), strerror.Text(err))
+			}
+			c.reportFailure(cur, childPath, nil, err)
+		} else {
+			childOK = c.walk(cur, fd, child, childPath, &childSt)
+		}
+
+		if seg != nil {
+			seg.finish(childOK)
+			queue = append(queue, seg)
+		} else if !childOK {
+			ok = false
+		}
+
+		for len(queue) > 0 && queue[0].finished() {
+			if !queue[0].flush(r) {
+				ok = false
+			}
+			queue = queue[1:]
+		}
+	}
+	for _, seg := range queue {
+		if !seg.flush(r) {
+			ok = false
+		}
+	}
+	unix.Close(fd)
+
+	if !c.changeOwner(r, dirfd, name, path, st) {
+		ok = false
+	}
+	return ok
+}
+
+// isRoot reports whether st is "/" and PreserveRoot is set. This catches
+// any name for it, like "/tmp/.." or a symbolic link followed with -H or
+// -L.
+func (c *chowner) isRoot(st *unix.Stat_t) bool {
+	return c.PreserveRoot &&
+		uint64(st.Dev) == c.rootDev && uint64(st.Ino) == c.rootIno
+}
+
+// statAt is unix.Fstatat, except that, like fts(3), a dangling symbolic
+// link that it was asked to follow is described as the link itself.
+// Whatever then tries to use the link's referent will say it's missing.
+func statAt(dirfd int, name string, st *unix.Stat_t, flags int) error {
+	err := unix.Fstatat(dirfd, name, st, flags)
+	if err == unix.ENOENT && flags&unix.AT_SYMLINK_NOFOLLOW == 0 {
+		if unix.Fstatat(dirfd, name, st, unix.AT_SYMLINK_NOFOLLOW) == nil &&
+			st.Mode&unix.S_IFMT == unix.S_IFLNK {
+			return nil
+		}
+	}
+	return err
+}
+
+// openDir opens the directory name, relative to dirfd, and returns the
+// descriptor and its entries, sorted. Unless follow is set it won't go
+// through a symbolic link, and it fails if the directory isn't the one
+// described by st any more.
+func openDir(dirfd int, name string, st *unix.Stat_t, follow bool) (int, []string, error) {
+	flags := unix.O_RDONLY | unix.O_DIRECTORY | unix.O_NONBLOCK | unix.O_CLOEXEC
+	if !follow {
+		flags |= unix.O_NOFOLLOW
+	}
+	fd, err := unix.Openat(dirfd, name, flags, 0)
+	if err != nil {
+		return -1, nil, err
+	}
+
+	fst := unix.Stat_t{}
+	if err = unix.Fstat(fd, &fst); err == nil && !sameInode(st, &fst) {
+		err = errReplaced
+	}
+
+	var names []string
+	buf := make([]byte, 8192)
+	for err == nil {
+		var n int
+		n, err = unix.ReadDirent(fd, buf)
+		if n <= 0 {
+			break
+		}
+		_, _, names = unix.ParseDirent(buf[:n], -1, names)
+	}
+	if err != nil {
+		unix.Close(fd)
+		return -1, nil, err
+	}
+	sort.Strings(names)
+	return fd, names, nil
+}
+
+// changeOwner changes the ownership of name, relative to dirfd, which was
+// st when we looked. fname is its name for messages.
+func (c *chowner) changeOwner(r *report, dirfd int, name, fname string, st *unix.Stat_t) bool {
+	var doChown bool
+	var failure error
+
+	symlinkChanged := true
+
+	// When affecting what a symbolic link points to, it's the
+	// referent's owner that matters. A dangling link has none; -v still
+	// describes the link, like GNU.
+	stat_t := *st
+	if c.Dereference && st.Mode&unix.S_IFMT == unix.S_IFLNK {
+		if err := unix.Fstatat(dirfd, name, &stat_t, 0); err != nil {
+			if !c.ForceSilent {
+				r.diag.Printf("cannot dereference %s: %s\n", quote.Name(fname), strerror.Text(err))
+			}
+			stat_t = *st
+			failure = err
+		}
+	}
+
+	// With a required owner or group, files that don't match are left
+	// alone. That isn't an error; -v reports them as retained.
+	matches := (c.FromUID == -1 || uint32(c.FromUID) == stat_t.Uid) &&
+		(c.FromGID == -1 || uint32(c.FromGID) == stat_t.Gid)
+
+	if failure != nil || !matches {
+		doChown = false
+	} else {
+		doChown = true
+	}
+
+	if doChown && !c.DryRun {
+		var chownErr error
+		if !c.Dereference {
+			chownErr = unix.Fchownat(dirfd, name, c.UID, c.GID, unix.AT_SYMLINK_NOFOLLOW)
+
+			// GNU's chown says it ignores any error due to lack of support.
+			// Apparently "POSIX requires this behavior for any top-level sym
+			// links with -h, and implies it's required for all symlinks."
+			if chownErr == unix.EOPNOTSUPP {
+				chownErr = nil
+				symlinkChanged = false
+			}
+		} else {
+			status, err := c.restrictedChown(dirfd, name, &stat_t)
+
+			switch status {
+			case rcOK:
+				break
+			case rcDoOrdinaryChown:
+				chownErr = unix.Fchownat(dirfd, name, c.UID, c.GID, 0)
+			case rcError:
+				chownErr = err
+			case rcInodeChanged:
+				// Like GNU, say nothing: the file we looked at is gone.
+				doChown = false
+				failure = errInodeChanged
+			case rcExcluded:
+				// The owner changed after we looked, and no longer
+				// matches.
+				doChown = false
+			}
+		}
+
+		if chownErr != nil {
+			if !c.ForceSilent {
+				what := "changing ownership of"
+				if c.UID == -1 {
+					what = "changing group of"
+				}
+				r.diag.Printf("%s %s: %s\n", what, quote.Name(fname), strerror.Text(chownErr))
+			}
+			failure = chownErr
+		}
+	}
+
+	changed := doChown && failure == nil && symlinkChanged &&
+		!((c.UID == -1 || uint32(c.UID) == stat_t.Uid) &&
+			(c.GID == -1 || uint32(c.GID) == stat_t.Gid))
+
+	ch := Change{
+		Path:   fname,
+		OldUID: int(stat_t.Uid),
+		OldGID: int(stat_t.Gid),
+		NewUID: int(stat_t.Uid),
+		NewGID: int(stat_t.Gid),
+		Err:    failure,
+	}
+	if failure != nil {
+		ch.Status = Failed
+	} else if !symlinkChanged {
+		ch.Status = NotApplied
+	} else if !changed {
+		ch.Status = Retained
+	} else {
+		ch.Status = Changed
+		if c.DryRun {
+			ch.Status = WouldChange
+		}
+		if c.UID != -1 {
+			ch.NewUID = c.UID
+		}
+		if c.GID != -1 {
+			ch.NewGID = c.GID
+		}
+	}
+
+	if changed && c.Verbosity != VOff || c.Verbosity == VHigh {
+		u, g := UIDToName(stat_t.Uid), GIDToName(stat_t.Gid)
+		c.describeChange(r, fname, ch.Status, &u, &g)
+	}
+	r.emit(ch)
+	return failure == nil
+}
+
+// reportFailure describes a file that couldn't be changed because it, or
+// its contents, couldn't be looked at. st is nil if it couldn't be
+// stat'd at all.
+func (c *chowner) reportFailure(r *report, path string, st *unix.Stat_t, err error) {
+	if c.Verbosity == VHigh {
+		c.describeChange(r, path, Failed, nil, nil)
+	}
+
+	ch := Change{
+		Path:   path,
+		Status: Failed,
+		OldUID: -1,
+		OldGID: -1,
+		NewUID: -1,
+		NewGID: -1,
+		Err:    err,
+	}
+	if st != nil {
+		ch.OldUID, ch.OldGID = int(st.Uid), int(st.Gid)
+		ch.NewUID, ch.NewGID = ch.OldUID, ch.OldGID
+	}
+	r.emit(ch)
+}
+
+// restrictedChown changes the owner of file, relative to cwd_fd, through
+// a descriptor so that it's the file we checked against the required
+// owner and group that gets changed, and not something swapped in after.
+// It gives up and asks for an ordinary chown if the file can't be opened.
+// The error goes with rcError.
+func (c *chowner) restrictedChown(cwd_fd int, file string, origStat *unix.Stat_t) (rcStatus, error) {
+	if c.FromUID == -1 && c.FromGID == -1 {
+		return rcDoOrdinaryChown, nil
+	}
+
+	openFlags := unix.O_NONBLOCK | unix.O_NOCTTY | unix.O_CLOEXEC
+	isReg := origStat.Mode&unix.S_IFMT == unix.S_IFREG
+	if !isReg {
+		if origStat.Mode&unix.S_IFMT == unix.S_IFDIR {
+			openFlags |= unix.O_DIRECTORY
+		} else {
+			return rcDoOrdinaryChown, nil
+		}
+	}
+
+	// Try for write access if we can't read a regular file.
+	fd, err := unix.Openat(cwd_fd, file, unix.O_RDONLY|openFlags, 0)
+	if err == unix.EACCES && isReg {
+		fd, err = unix.Openat(cwd_fd, file, unix.O_WRONLY|openFlags, 0)
+	}
+	if err != nil {
+		if err == unix.EACCES {
+			return rcDoOrdinaryChown, nil
+		}
+		return rcError, err
+	}
+
+	status := rcOK
+	fstat := unix.Stat_t{}
+	if err = unix.Fstat(fd, &fstat); err != nil {
+		status = rcError
+	} else if !sameInode(origStat, &fstat) {
+		status = rcInodeChanged
+	} else if (c.FromUID == -1 || uint32(c.FromUID) == fstat.Uid) && (c.FromGID == -1 || uint32(c.FromGID) == fstat.Gid) { // Sneaky chown lol
+		if err = unix.Fchown(fd, c.UID, c.GID); err != nil {
+			status = rcError
+		}
+	} else {
+		status = rcExcluded
+	}
+	if cerr := unix.Close(fd); cerr != nil && status != rcError {
+		return rcError, cerr
+	}
+	return status, err
+}
+
+// sameInode reports whether a and b describe the same file.
+func sameInode(a, b *unix.Stat_t) bool {
+	return uint64(a.Dev) == uint64(b.Dev) && uint64(a.Ino) == uint64(b.Ino)
+}
+
+func (c *chowner) describeChange(r *report, file string, changed Status, olduser, oldgroup *string) {
+	file = quote.Name(file)
+	if changed == NotApplied {
+		fmt.Fprintf(r.out, "neither symbolic link %s nor referent has been changed\n", file)
+		return
+	}
+
+	// Only mention the old values of what we were asked to change.
+	user, group := c.UserName, c.GroupName
+	if user == nil && c.UID != -1 {
+		id := strconv.Itoa(c.UID)
+		user = &id
+	}
+	if group == nil && c.GID != -1 {
+		id := strconv.Itoa(c.GID)
+		group = &id
+	}
+	if user == nil {
+		olduser = nil
+	}
+	if group == nil {
+		oldgroup = nil
+	}
+	spec := userGroupStr(user, group)
+	oldspec := userGroupStr(olduser, oldgroup)
+
+	did := "changed"
+	if changed == WouldChange {
+		did = "would change"
+	}
+
+	switch changed {
+	case Changed, WouldChange:
+		if user != nil {
+			fmt.Fprintf(r.out, "%s ownership of %s from %s to %s\n", did, file, oldspec, spec)
+		} else if group != nil {
+			fmt.Fprintf(r.out, "%s group of %s from %s to %s\n", did, file, oldspec, spec)
+		} else {
+			fmt.Fprintf(r.out, "no change to ownership of %s\n", file)
+		}
+	case Failed:
+		if olduser != nil || oldgroup != nil {
+			if user != nil {
+				fmt.Fprintf(r.out, "failed to change ownership of %s from %s to %s\n", file, oldspec, spec)
+			} else if group != nil {
+				fmt.Fprintf(r.out, "failed to change group of %s from %s to %s\n", file, oldspec, spec)
+			} else {
+				fmt.Fprintf(r.out, "failed to change ownership of %s\n", file)
+			}
+		} else {
+			if user != nil {
+				fmt.Fprintf(r.out, "failed to change ownership of %s to %s\n", file, spec)
+			} else if group != nil {
+				fmt.Fprintf(r.out, "failed to change group of %s to %s\n", file, spec)
+			} else {
+				fmt.Fprintf(r.out, "failed to change ownership of %s\n", file)
+			}
+		}
+	case Retained:
+		if user != nil {
+			fmt.Fprintf(r.out, "ownership of %s retained as %s\n", file, oldspec)
+		} else if group != nil {
+			fmt.Fprintf(r.out, "group of %s retained as %s\n", file, oldspec)
+		} else {
+			fmt.Fprintf(r.out, "ownership of %s retained\n", file)
+		}
+	}
+}
+
+// userGroupStr formats a user and group for -v output. A nil part is
+// left out, but an empty one isn't: a chown of just ":staff" is shown as
+// ":staff", like GNU.
+func userGroupStr(user, group *string) string {
+	switch {
+	case user != nil && group != nil:
+		return *user + ":" + *group
+	case user != nil:
+		return *user
+	case group != nil:
+		return *group
+	}
+	return ""
+}
+
+// ErrNoSuchID is returned by LookupUser and LookupGroup for a name that
+// isn't known and isn't a number.
+var ErrNoSuchID = errors.New("can't find user/group/uid/gid")
+
+// LookupUser returns the uid of the user name. Like GNU, a name that no
+// user has, or that starts with '+', is taken as the uid itself, whether
+// or not the system knows it, and numeric reports that it was.
+func LookupUser(name string) (uid int, numeric bool, err error) {
+	if !strings.HasPrefix(name, "+") {
+		if u, err := user.Lookup(name); err == nil {
+			uid, _ = strconv.Atoi(u.Uid)
+			return uid, false, nil
+		}
+	}
+	uid, err = parseID(name)
+	return uid, true, err
+}
+
+// LookupGroup is LookupUser for groups.
+func LookupGroup(name string) (gid int, numeric bool, err error) {
+	if !strings.HasPrefix(name, "+") {
+		if g, err := user.LookupGroup(name); err == nil {
+			gid, _ = strconv.Atoi(g.Gid)
+			return gid, false, nil
+		}
+	}
+	gid, err = parseID(name)
+	return gid, true, err
+}
+
+// parseID parses a numeric uid or gid the way GNU's xstrtoumax does,
+// allowing leading space and a '+'. The largest value is -1 as an id_t,
+// which means "unchanged", so it isn't allowed.
+func parseID(s string) (int, error) {
+	s = strings.TrimLeft(s, " \t\n\v\f\r")
+	n, err := strconv.ParseUint(strings.TrimPrefix(s, "+"), 10, 32)
+	if err != nil || n == math.MaxUint32 {
+		return -1, ErrNoSuchID
+	}
+	return int(n), nil
+}
+
+// GIDToName returns the name of gid's group, or the number if it has
+// no name.
+func GIDToName(gid uint32) string {
+	id := strconv.FormatUint(uint64(gid), 10)
+	if g, err := user.LookupGroupId(id); err == nil {
+		return g.Name
+	}
+	return id
+}
+
+// UIDToName returns the name of uid's user, or the number if it has
+// no name.
+func UIDToName(uid uint32) string {
+	id := strconv.FormatUint(uint64(uid), 10)
+	if u, err := user.LookupId(id); err == nil {
+		return u.Username
+	}
+	return id
+}
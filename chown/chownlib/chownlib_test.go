@@ -0,0 +1,107 @@
+package chownlib
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// tree makes a small hierarchy to walk, returning its root.
+func tree(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "chownlib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []string{"a/b", "c", "d"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"a/b/x", "a/y", "c/z", "w"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRunOrder(t *testing.T) {
+	dir := tree(t)
+	defer os.RemoveAll(dir)
+
+	want := []string{"a/b/x", "a/b", "a/y", "a", "c/z", "c", "d", "w", ""}
+	for _, jobs := range []int{1, 4} {
+		var got []string
+		opt := NewOptions()
+		opt.UID = os.Getuid()
+		opt.Recursive = true
+		opt.DryRun = true
+		opt.Jobs = jobs
+		opt.Report = func(ch Change) {
+			if ch.Status != Retained {
+				t.Errorf("%s: got %v, want %v", ch.Path, ch.Status, Retained)
+			}
+			rel, _ := filepath.Rel(dir, ch.Path)
+			if rel == "." {
+				rel = ""
+			}
+			got = append(got, filepath.ToSlash(rel))
+		}
+		if err := opt.Run([]string{dir}); err != nil {
+			t.Fatalf("jobs=%d: %v", jobs, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("jobs=%d: got %q, want %q", jobs, got, want)
+		}
+	}
+}
+
+func TestRunMissing(t *testing.T) {
+	dir := tree(t)
+	defer os.RemoveAll(dir)
+
+	missing := filepath.Join(dir, "missing")
+	var changes []Change
+	opt := NewOptions()
+	opt.GID = os.Getgid()
+	opt.Report = func(ch Change) { changes = append(changes, ch) }
+
+	err := opt.Run([]string{missing, filepath.Join(dir, "w")})
+	if e, ok := err.(*os.PathError); !ok || e.Path != missing {
+		t.Fatalf("got error %v, want one for %s", err, missing)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes, want 2", len(changes))
+	}
+	if ch := changes[0]; ch.Status != Failed || ch.OldUID != -1 || ch.Err == nil {
+		t.Errorf("missing file: got %+v", ch)
+	}
+	if ch := changes[1]; ch.Status != Retained || ch.NewGID != os.Getgid() {
+		t.Errorf("existing file: got %+v", ch)
+	}
+}
+
+func TestLookupNumeric(t *testing.T) {
+	for _, tt := range []struct {
+		in string
+		id int
+		ok bool
+	}{
+		{"12345", 12345, true},
+		{"+0", 0, true},
+		{" 00012", 12, true},
+		{"65534", 65534, true},
+		{"4294967295", -1, false},
+		{"-1", -1, false},
+		{"0x10", -1, false},
+		{"+", -1, false},
+	} {
+		id, numeric, err := LookupUser(tt.in)
+		if (err == nil) != tt.ok || id != tt.id || !numeric {
+			t.Errorf("LookupUser(%q) = %d, %v, %v; want %d, true, ok=%v",
+				tt.in, id, numeric, err, tt.id, tt.ok)
+		}
+	}
+}
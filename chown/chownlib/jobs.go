@@ -0,0 +1,90 @@
+package chownlib
+
+import (
+	"bytes"
+	"io"
+	"log"
+)
+
+// A report is where part of a walk writes its -v output and diagnostics,
+// and sends its Changes.
+type report struct {
+	out  io.Writer
+	diag *log.Logger
+	emit func(Change)
+}
+
+// A segment holds the output of a subtree walked out of turn, on another
+// goroutine or after one, until its parent can write it in order.
+type segment struct {
+	report
+	out, diag bytes.Buffer
+	changes   []pending
+	ok        bool
+	done      chan struct{}
+}
+
+// A pending Change is sent once the first at bytes of its segment's
+// output are written, so it stays in order with it.
+type pending struct {
+	Change
+	at int
+}
+
+// newSegment returns an empty segment for part of parent's walk.
+func newSegment(parent *report) *segment {
+	seg := &segment{done: make(chan struct{})}
+	seg.report = report{
+		out:  &seg.out,
+		diag: log.New(&seg.diag, parent.diag.Prefix(), 0),
+		emit: func(ch Change) {
+			seg.changes = append(seg.changes, pending{ch, seg.out.Len()})
+		},
+	}
+	return seg
+}
+
+// finish records the subtree's result, once its walk is over.
+func (seg *segment) finish(ok bool) {
+	seg.ok = ok
+	close(seg.done)
+}
+
+func (seg *segment) finished() bool {
+	select {
+	case <-seg.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// flush waits for the subtree to finish, copies its output to r and
+// returns its result.
+func (seg *segment) flush(r *report) bool {
+	<-seg.done
+	out, n := seg.out.Bytes(), 0
+	for _, p := range seg.changes {
+		r.out.Write(out[n:p.at])
+		r.emit(p.Change)
+		n = p.at
+	}
+	r.out.Write(out[n:])
+	r.diag.Writer().Write(seg.diag.Bytes())
+	return seg.ok
+}
+
+// tryGo runs f on a goroutine of its own if Jobs leaves one free, and
+// reports whether it did.
+func (c *chowner) tryGo(f func()) bool {
+	select {
+	case c.workers <- struct{}{}:
+		go func() {
+			defer func() { <-c.workers }()
+			f()
+		}()
+		return true
+	default:
+		return false
+	}
+}
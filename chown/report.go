@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/EricLagerg/go-coreutils/chown/chownlib"
+)
+
+// A record is the --report=json description of one file. The old
+// ownership is null if the file couldn't be stat'd, and the new one is
+// what it is afterwards.
+type record struct {
+	Path   string `json:"path"`
+	OldUID *int   `json:"old_uid"`
+	OldGID *int   `json:"old_gid"`
+	NewUID *int   `json:"new_uid"`
+	NewGID *int   `json:"new_gid"`
+	Status string `json:"status"`
+}
+
+// writeRecord writes ch to w as a JSON object on a line of its own.
+func writeRecord(w io.Writer, ch chownlib.Change) {
+	rec := record{
+		Path:   ch.Path,
+		OldUID: id(ch.OldUID),
+		OldGID: id(ch.OldGID),
+		NewUID: id(ch.NewUID),
+		NewGID: id(ch.NewGID),
+		Status: ch.Status.String(),
+	}
+	b, _ := json.Marshal(rec)
+	w.Write(append(b, '\n'))
+}
+
+// id returns a pointer to n, or nil if n is -1, meaning unknown.
+func id(n int) *int {
+	if n == -1 {
+		return nil
+	}
+	return &n
+}
@@ -23,6 +23,7 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"runtime"
 	"strings"
 
 	flag "github.com/ogier/pflag"
@@ -38,11 +39,21 @@ Mandatory arguments to long options are mandatory for short options too.
   -0, --null           end each output line with NUL, not newline
   -u, --unset=NAME     remove variable from the environment
   -s, --set=NAME       set variable in the environment
+  -S, --split-string=S  process and split S into separate arguments;
+                        used to pass multiple arguments on shebang lines
+      --block-signal[=SIG]    block delivery of SIG signal(s) to COMMAND
+      --default-signal[=SIG]  reset handling of SIG signal(s) to the default
+      --ignore-signal[=SIG]   set handling of SIG signal(s) to do nothing
+      --list-signal-handling  list non default signal handling to stderr
       --help           display this help and exit
       --version        output version information and exit
 
 A mere - implies -i.  If no COMMAND, print the resulting environment.
 
+SIG may be a signal name like 'PIPE', or a signal number like '13'.
+Without SIG, all known signals are included.  Multiple signals can be
+comma-separated.  An empty SIG argument is a no-op.
+
 Report wc bugs to ericscottlagergren@gmail.com
 Go coreutils home page: <https://www.github.com/EricLagerg/go-coreutils/>
 `
@@ -62,46 +73,30 @@ var (
 	ignore  = flag.BoolP("ignore-environment", "i", false, "")
 	version = flag.BoolP("version", "v", false, "")
 
-	fatal = log.New(os.Stderr, "", log.Lshortfile)
-	// fatal = log.New(os.Stderr, "", 0)
+	fatal = log.New(os.Stderr, "env: ", 0)
 
 	env = os.Environ()
 )
 
-// Run a command, waiting for it to finish. Will first run CMD's path;
-// failing that, will lookup the path and attempt to do the same.
-func execvp(cmd *exec.Cmd) error {
-	if err := cmd.Start(); err == nil {
-
-		// Wait for command to finish
-		return cmd.Wait()
-
-	} else {
-
-		// Didn't work? Search for the executable's path
-		path, err := exec.LookPath(cmd.Path)
-		if err != nil {
-			return err
-		}
-
-		// Reset our path
-		cmd.Path = path
-
-		// Try again with the executable found in $PATH
-		err = cmd.Start()
-		if err != nil {
-			return err
-		}
-
-		return cmd.Wait()
-	}
-}
-
 func main() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "%s", Help)
 		os.Exit(1)
 	}
+
+	// -S has to be expanded before the flags are parsed, since the
+	// string it splits can hold more flags.
+	args, err := splitArgs(os.Args[1:])
+	if err != nil {
+		fatal.Fatalln(err)
+	}
+	args, err = signalArgs(args)
+	if err != nil {
+		fatal.Printf("%v\n", err)
+		fatal.Fatalln("Try 'env --help' for more information.")
+	}
+	os.Args = append(os.Args[:1], args...)
+
 	flag.Parse()
 
 	if *version {
@@ -113,6 +108,17 @@ func main() {
 		os.Unsetenv(*unset)
 	}
 
+	// Like GNU, apply and list the signal options whether or not there's
+	// a COMMAND. The signal mask belongs to the thread, so stay on the one
+	// that sets it until it execs.
+	runtime.LockOSThread()
+	if err := setSignals(); err != nil {
+		fatal.Fatalln(err)
+	}
+	if listSignals {
+		listSignalHandling()
+	}
+
 	cmd := new(exec.Cmd)
 	cmd.Env = env
 
@@ -0,0 +1,21 @@
+// +build !windows
+
+package main
+
+import (
+	"os/exec"
+
+	"golang.org/x/sys/unix"
+)
+
+// Replace env with a command, like execvp(3): CMD is looked up in $PATH
+// unless it contains a slash. The command inherits env's signal
+// dispositions and mask, which is what the signal options rely on, so
+// this must run on the thread main locked.
+func execvp(cmd *exec.Cmd) error {
+	path, err := exec.LookPath(cmd.Path)
+	if err != nil {
+		return err
+	}
+	return unix.Exec(path, cmd.Args, cmd.Env)
+}
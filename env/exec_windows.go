@@ -0,0 +1,20 @@
+package main
+
+import "os/exec"
+
+// Run a command, waiting for it to finish. Like execvp(3), CMD is
+// looked up in $PATH unless it contains a slash.
+func execvp(cmd *exec.Cmd) error {
+	path, err := exec.LookPath(cmd.Path)
+	if err != nil {
+		return err
+	}
+	cmd.Path = path
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// Wait for command to finish
+	return cmd.Wait()
+}
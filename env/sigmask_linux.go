@@ -0,0 +1,31 @@
+package main
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Bits in each word of a unix.Sigset_t.
+const maskBits = uint(8 * unsafe.Sizeof(unix.Sigset_t{}.Val[0]))
+
+// blockSignals adds sigs to the calling thread's signal mask.
+func blockSignals(sigs []syscall.Signal) error {
+	var set unix.Sigset_t
+	for _, s := range sigs {
+		n := uint(s - 1)
+		set.Val[n/maskBits] |= 1 << (n % maskBits)
+	}
+	return unix.PthreadSigmask(unix.SIG_BLOCK, &set, nil)
+}
+
+// isBlocked reports whether s is in the calling thread's signal mask.
+func isBlocked(s syscall.Signal) bool {
+	var set unix.Sigset_t
+	if unix.PthreadSigmask(unix.SIG_BLOCK, nil, &set) != nil {
+		return false
+	}
+	n := uint(s - 1)
+	return set.Val[n/maskBits]>>(n%maskBits)&1 != 0
+}
@@ -0,0 +1,16 @@
+// +build !linux,!windows
+
+package main
+
+import (
+	"errors"
+	"syscall"
+)
+
+func blockSignals(sigs []syscall.Signal) error {
+	return errors.New("blocking signals is not supported on this system")
+}
+
+func isBlocked(s syscall.Signal) bool {
+	return false
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"syscall"
+
+	"github.com/EricLagerg/go-coreutils/internal/quote"
+)
+
+// What to do with a set of signals before running COMMAND.
+type sigAction int
+
+const (
+	sigDefault sigAction = iota
+	sigIgnore
+	sigBlock
+)
+
+var sigFlags = map[string]sigAction{
+	"--default-signal": sigDefault,
+	"--ignore-signal":  sigIgnore,
+	"--block-signal":   sigBlock,
+}
+
+type sigOpt struct {
+	action sigAction
+	sigs   []syscall.Signal
+	all    bool // no list was given, so every signal is affected
+}
+
+var (
+	sigOpts     []sigOpt
+	listSignals bool
+)
+
+// signalArgs removes the signal options from env's own options and
+// records them in sigOpts, in order, so that a later option overrides an
+// earlier one for the same signal. They're handled here instead of by
+// the flag parser since their argument is optional and, when given,
+// must be attached with '='. The options are ended with "--" so that the
+// flag parser leaves COMMAND's own options alone.
+func signalArgs(args []string) ([]string, error) {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return append(out, args[i:]...), nil
+		case arg == "-" || !strings.HasPrefix(arg, "-"):
+			out = append(out, "--")
+			return append(out, args[i:]...), nil
+		case arg == "--list-signal-handling":
+			listSignals = true
+			continue
+		case arg == "-u" || arg == "--unset":
+			out = append(out, arg)
+			if i+1 < len(args) {
+				i++
+				out = append(out, args[i])
+			}
+			continue
+		}
+
+		name, list := arg, ""
+		all := true
+		if j := strings.IndexByte(arg, '='); j >= 0 {
+			name, list, all = arg[:j], arg[j+1:], false
+		}
+		action, ok := sigFlags[name]
+		if !ok {
+			out = append(out, arg)
+			continue
+		}
+
+		opt := sigOpt{action: action, all: all}
+		for _, s := range strings.Split(list, ",") {
+			if s == "" {
+				continue
+			}
+			sig, err := parseSignal(s)
+			if err != nil {
+				return nil, err
+			}
+			opt.sigs = append(opt.sigs, sig)
+		}
+		sigOpts = append(sigOpts, opt)
+	}
+	return out, nil
+}
+
+func invalidSignal(s string) error {
+	return fmt.Errorf("%s: invalid signal", quote.Name(s))
+}
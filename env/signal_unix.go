@@ -0,0 +1,118 @@
+// +build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/EricLagerg/go-coreutils/internal/sig"
+)
+
+func parseSignal(s string) (syscall.Signal, error) {
+	n, ok := sig.Parse(s)
+	if !ok || n == 0 {
+		return 0, invalidSignal(s)
+	}
+	return n, nil
+}
+
+// allSignals returns every signal whose disposition can be changed.
+func allSignals() []syscall.Signal {
+	var sigs []syscall.Signal
+	for s := syscall.Signal(1); s <= sig.Max; s++ {
+		if s != syscall.SIGKILL && s != syscall.SIGSTOP && sig.Name(s) != "" {
+			sigs = append(sigs, s)
+		}
+	}
+	return sigs
+}
+
+// The Go runtime keeps its own handlers for these, whatever os/signal is
+// told. signal.Ignore on one only changes what signal.Ignored reports, and
+// COMMAND starts with the default action, since caught signals are reset
+// by execve(2).
+var runtimeSignals = map[string]bool{
+	"ILL":    true,
+	"TRAP":   true,
+	"EMT":    true,
+	"BUS":    true,
+	"FPE":    true,
+	"SEGV":   true,
+	"STKFLT": true,
+	"PROF":   true,
+	"SYS":    true,
+	"RTMIN":  true, // signal 34 on Linux, which musl uses itself
+}
+
+// setSignals applies sigOpts to the calling thread, which must go on to
+// exec COMMAND.
+//
+// Ignored signals stay ignored across execve(2). For the default action
+// we have the runtime catch the signal instead, since caught signals are
+// reset to SIG_DFL by execve(2); that includes signals we were started
+// with ignored. The signal mask is per thread and survives execve(2) too.
+//
+// The Go runtime won't let go of the signals it needs itself (SEGV, BUS,
+// FPE, PROF and so on), so those can't be ignored. They're left alone
+// rather than listed as ignored when they aren't.
+func setSignals() error {
+	for _, opt := range sigOpts {
+		sigs := opt.sigs
+		if opt.all {
+			sigs = allSignals()
+		}
+		if opt.action == sigBlock {
+			if err := blockSignals(sigs); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, s := range sigs {
+			if s == syscall.SIGKILL || s == syscall.SIGSTOP {
+				return fmt.Errorf("failed to set signal action for signal %d: %v",
+					int(s), syscall.EINVAL)
+			}
+			if opt.action == sigIgnore {
+				if !runtimeSignals[sig.Name(s)] {
+					signal.Ignore(s)
+				}
+			} else {
+				signal.Notify(make(chan os.Signal, 1), s)
+			}
+		}
+	}
+	return nil
+}
+
+// listSignalHandling writes each signal that COMMAND will start with
+// blocked or ignored to standard error, in the same format as GNU.
+func listSignalHandling() {
+	for s := syscall.Signal(1); s <= sig.Max; s++ {
+		blocked, ignored := "", ""
+		if isBlocked(s) {
+			blocked = "BLOCK"
+		}
+		if signal.Ignored(s) {
+			ignored = "IGNORE"
+		}
+		if blocked == "" && ignored == "" {
+			continue
+		}
+		sep := ""
+		if blocked != "" && ignored != "" {
+			sep = ","
+		}
+
+		name := sig.Name(s)
+		if name == "" {
+			name = strconv.Itoa(int(s))
+		}
+		fmt.Fprintf(os.Stderr, "%-10s (%2d): %s%s%s\n",
+			name, int(s), blocked, sep, ignored)
+	}
+}
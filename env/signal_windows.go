@@ -0,0 +1,19 @@
+package main
+
+import (
+	"errors"
+	"syscall"
+)
+
+func parseSignal(s string) (syscall.Signal, error) {
+	return 0, errors.New("signal options are not supported on Windows")
+}
+
+func setSignals() error {
+	if len(sigOpts) > 0 {
+		return errors.New("signal options are not supported on Windows")
+	}
+	return nil
+}
+
+func listSignalHandling() {}
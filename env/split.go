@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// splitArgs replaces each -S/--split-string option among env's own
+// options, i.e. those before the first operand, with the words of its
+// argument. The words are scanned again, so they may be options too.
+// This is what makes "#!/usr/bin/env -S cmd --flag" work: the kernel
+// passes everything after the interpreter as a single argument.
+func splitArgs(args []string) ([]string, error) {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		var str string
+		switch {
+		case arg == "--" || arg == "-" || !strings.HasPrefix(arg, "-"):
+			return append(out, args[i:]...), nil
+		case arg == "-S" || arg == "--split-string":
+			if i+1 == len(args) {
+				// Let the flag parser report the missing argument.
+				return append(out, arg), nil
+			}
+			i++
+			str = args[i]
+		case strings.HasPrefix(arg, "--split-string="):
+			str = arg[len("--split-string="):]
+		case strings.HasPrefix(arg, "-S"):
+			str = arg[2:]
+		case arg == "-u" || arg == "--unset":
+			out = append(out, arg)
+			if i+1 < len(args) {
+				i++
+				out = append(out, args[i])
+			}
+			continue
+		default:
+			out = append(out, arg)
+			continue
+		}
+
+		words, err := splitString(str)
+		if err != nil {
+			return nil, err
+		}
+		args = append(words, args[i+1:]...)
+		i = -1
+	}
+	return out, nil
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+	return false
+}
+
+func isNameChar(c byte, first bool) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' ||
+		!first && '0' <= c && c <= '9'
+}
+
+// splitString splits s into words using GNU env's -S rules: words are
+// separated by whitespace; single quotes quote everything but \\ and \';
+// double quotes allow escapes and ${VAR}; outside quotes \_ separates
+// words, # starts a comment at the beginning of a word and \c ends the
+// string.
+func splitString(s string) ([]string, error) {
+	var (
+		words  []string
+		cur    []byte
+		inWord bool // cur holds a word, possibly an empty one ('')
+		sq, dq bool
+	)
+
+	flush := func() {
+		if inWord {
+			words = append(words, string(cur))
+			cur = cur[:0]
+			inWord = false
+		}
+	}
+
+scan:
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case sq:
+			if c == '\'' {
+				sq = false
+				continue
+			}
+			if c == '\\' && i+1 < len(s) && (s[i+1] == '\\' || s[i+1] == '\'') {
+				i++
+				c = s[i]
+			}
+			cur = append(cur, c)
+		case c == '\'' && !dq:
+			sq, inWord = true, true
+		case c == '"':
+			dq, inWord = !dq, true
+		case isSpace(c) && !dq:
+			flush()
+		case c == '#' && !dq && !inWord:
+			break scan
+		case c == '\\':
+			i++
+			if i == len(s) {
+				return nil, errors.New("invalid backslash at end of string in -S")
+			}
+			switch e := s[i]; e {
+			case 'c':
+				if dq {
+					return nil, errors.New("'\\c' must not appear in double-quoted -S string")
+				}
+				break scan
+			case '_':
+				if !dq {
+					flush()
+					continue
+				}
+				cur = append(cur, ' ')
+			case '"', '\'', '\\', '$', '#':
+				cur = append(cur, e)
+			case 'f':
+				cur = append(cur, '\f')
+			case 'n':
+				cur = append(cur, '\n')
+			case 'r':
+				cur = append(cur, '\r')
+			case 't':
+				cur = append(cur, '\t')
+			case 'v':
+				cur = append(cur, '\v')
+			default:
+				return nil, fmt.Errorf("invalid sequence '\\%c' in -S", e)
+			}
+			inWord = true
+		case c == '$':
+			j := i + 2
+			for j < len(s) && isNameChar(s[j], j == i+2) {
+				j++
+			}
+			if i+1 == len(s) || s[i+1] != '{' || j == i+2 ||
+				j == len(s) || s[j] != '}' {
+				return nil, fmt.Errorf("only ${VARNAME} expansion is supported, error at: %s", s[i:])
+			}
+			cur = append(cur, os.Getenv(s[i+2:j])...)
+			inWord = true
+			i = j
+		default:
+			cur = append(cur, c)
+			inWord = true
+		}
+	}
+
+	if sq || dq {
+		return nil, errors.New("no terminating quote in -S string")
+	}
+	flush()
+	return words, nil
+}
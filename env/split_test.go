@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+var splitTests = []struct {
+	in  string
+	out []string
+}{
+	{"", nil},
+	{"  a  b\tc ", []string{"a", "b", "c"}},
+	{`'a b' "c d"`, []string{"a b", "c d"}},
+	{`'it\'s' 'x\ny'`, []string{"it's", `x\ny`}},
+	{`''`, []string{""}},
+	{`a\_b "a\_b"`, []string{"a", "b", "a b"}},
+	{`"tab\there" \$HOME`, []string{"tab\there", "$HOME"}},
+	{`a #comment`, []string{"a"}},
+	{`a#b`, []string{"a#b"}},
+	{`a \c b c`, []string{"a"}},
+	{`${SPLIT_TEST}x "${SPLIT_TEST}"`, []string{"foo barx", "foo bar"}},
+	{`${SPLIT_UNSET}`, []string{""}},
+}
+
+func TestSplitString(t *testing.T) {
+	os.Setenv("SPLIT_TEST", "foo bar")
+	os.Unsetenv("SPLIT_UNSET")
+	for _, tt := range splitTests {
+		got, err := splitString(tt.in)
+		if err != nil {
+			t.Errorf("splitString(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.out) {
+			t.Errorf("splitString(%q) = %q, want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestSplitStringErrors(t *testing.T) {
+	for _, in := range []string{`'a`, `"a`, `a\`, `$HOME`, `${}`, `${A`, `\q`, `"\c"`} {
+		if _, err := splitString(in); err == nil {
+			t.Errorf("splitString(%q) succeeded", in)
+		}
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	in := []string{"-S", "-i A=1 cmd --flag", "script"}
+	want := []string{"-i", "A=1", "cmd", "--flag", "script"}
+	got, err := splitArgs(in)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("splitArgs(%q) = %q, %v; want %q", in, got, err, want)
+	}
+
+	// Shebang style, and -S after the first operand is left alone.
+	in = []string{"-Scmd -S x", "y"}
+	want = []string{"cmd", "-S", "x", "y"}
+	got, err = splitArgs(in)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("splitArgs(%q) = %q, %v; want %q", in, got, err, want)
+	}
+}
+
+func TestSignalArgs(t *testing.T) {
+	in := []string{"--ignore-signal=INT,HUP", "-u", "--block-signal", "--default-signal", "-i", "cmd", "--ignore-signal"}
+	want := []string{"-u", "--block-signal", "-i", "--", "cmd", "--ignore-signal"}
+	got, err := signalArgs(in)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("signalArgs(%q) = %q, %v; want %q", in, got, err, want)
+	}
+	if len(sigOpts) != 2 || len(sigOpts[0].sigs) != 2 || sigOpts[0].all || !sigOpts[1].all {
+		t.Errorf("signalArgs(%q) recorded %v", in, sigOpts)
+	}
+
+	for _, in := range []string{"--ignore-signal=FOO", "--block-signal=0"} {
+		if _, err := signalArgs([]string{in}); err == nil {
+			t.Errorf("signalArgs(%q) succeeded", in)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+/*
+	Go coreutils -- close standard output, reporting write errors
+
+	Copyright (C) 2015 Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package closeout makes sure a program's output actually got written,
+// like gnulib's close_stdout. A buffered writer's errors only show up
+// when it's flushed, and some (e.g. on NFS) only when the file is
+// closed, so a program that doesn't check both can exit 0 with its
+// output truncated by a full disk.
+package closeout
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Stdout flushes w, if it isn't nil, and closes standard output. It
+// returns a "write error" describing the first thing that failed. The
+// caller should report it and exit non-zero.
+//
+// Call it once all output has been written. Like GNU, standard output
+// already being closed (e.g. "prog >&-") is only an error if there was
+// something to write.
+func Stdout(w *bufio.Writer) error {
+	pending := false
+	if w != nil {
+		pending = w.Buffered() > 0
+		if err := w.Flush(); err != nil {
+			return WriteError(err)
+		}
+	}
+	if err := os.Stdout.Close(); err != nil {
+		if e, ok := err.(*os.PathError); ok && e.Err == syscall.EBADF && !pending {
+			return nil
+		}
+		return WriteError(err)
+	}
+	return nil
+}
+
+// WriteError describes err, an error writing output, the way GNU does.
+func WriteError(err error) error {
+	if e, ok := err.(*os.PathError); ok {
+		err = e.Err
+	}
+	return fmt.Errorf("write error: %v", err)
+}
@@ -0,0 +1,39 @@
+// +build !windows
+
+package closeout
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// DieOfSIGPIPE kills the program with SIGPIPE, as writing to a broken pipe
+// does by default, so the parent sees the same death it would from GNU.
+// Call it after an EPIPE the runtime didn't see: it only dies of SIGPIPE
+// for writes to standard output or error through an *os.File, not for
+// other files or for splice(2) and friends. If the program ignores
+// SIGPIPE it returns, and the caller should report the error as usual.
+//
+// The runtime catches SIGPIPE and throws away one sent by kill(2), so
+// instead point standard output at a pipe with no reader and write to it.
+func DieOfSIGPIPE() {
+	if signal.Ignored(syscall.SIGPIPE) {
+		return
+	}
+	signal.Reset(syscall.SIGPIPE)
+
+	var p [2]int
+	if unix.Pipe(p[:]) == nil {
+		unix.Close(p[0])
+		if unix.Dup2(p[1], 1) == nil {
+			os.Stdout.Write([]byte{0})
+		}
+	}
+
+	// Should the write somehow not kill us, exit the way a shell
+	// reports death by SIGPIPE.
+	os.Exit(128 + int(syscall.SIGPIPE))
+}
@@ -0,0 +1,12 @@
+package closeout
+
+import (
+	"os"
+	"syscall"
+)
+
+// DieOfSIGPIPE exits the way a shell reports death by SIGPIPE, which
+// Windows doesn't have. It doesn't return.
+func DieOfSIGPIPE() {
+	os.Exit(128 + int(syscall.SIGPIPE))
+}
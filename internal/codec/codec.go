@@ -0,0 +1,219 @@
+/*
+	Go coreutils -- shared base64/base32/basenc codec
+
+	Copyright (C) 2015 Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package codec implements the streaming encode/decode loops shared by
+// base64 and basenc.
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrInvalidInput is returned when decoding malformed input, or when
+// encoding input whose length the encoding can't represent (z85).
+var ErrInvalidInput = errors.New("invalid input")
+
+// An Encoding converts between binary data and text, a block at a time.
+type Encoding interface {
+	// Blocks returns the block sizes of the encoding: every dec bytes of
+	// binary data become enc bytes of text.
+	Blocks() (dec, enc int)
+
+	// EncodedLen and DecodedLen return the maximum length of the result
+	// of encoding or decoding n bytes.
+	EncodedLen(n int) int
+	DecodedLen(n int) int
+
+	// Encode encodes src into dst. Only the final call for a stream may
+	// pass a partial block.
+	Encode(dst, src []byte) error
+
+	// Decode decodes src into dst, returning the number of bytes written.
+	// Only the final call for a stream may pass a partial block.
+	Decode(dst, src []byte) (int, error)
+
+	// Valid reports whether c can appear in encoded text, padding
+	// included.
+	Valid(c byte) bool
+}
+
+// bufSize is the amount of input read at once. It's large enough that
+// the per-call overhead of reads, writes and the block encoders vanishes
+// when converting big files.
+const bufSize = 64 * 1024
+
+// Encode reads r until EOF and writes its encoding to w, breaking lines
+// after wrap characters. If wrap is 0 lines aren't broken, and no final
+// newline is written.
+func Encode(w io.Writer, r io.Reader, enc Encoding, wrap int) error {
+	dec, _ := enc.Blocks()
+
+	// Read in whole blocks so only the last chunk can need padding.
+	in := make([]byte, bufSize/dec*dec)
+	text := make([]byte, enc.EncodedLen(len(in)))
+
+	// Wrapped output for one chunk, newlines included, so each chunk
+	// costs a single write.
+	out := text
+	if wrap > 0 {
+		out = make([]byte, len(text)+len(text)/wrap+1)
+	}
+	col := 0
+
+	for {
+		n, rerr := io.ReadFull(r, in)
+		if n > 0 {
+			m := enc.EncodedLen(n)
+			if err := enc.Encode(text[:m], in[:n]); err != nil {
+				return err
+			}
+
+			p := text[:m]
+			if wrap > 0 {
+				p, col = wrapLines(out, p, wrap, col)
+			}
+			if _, err := w.Write(p); err != nil {
+				return err
+			}
+		}
+		if rerr == io.EOF || rerr == io.ErrUnexpectedEOF {
+			break
+		}
+		if rerr != nil {
+			return rerr
+		}
+	}
+
+	if wrap > 0 && col > 0 {
+		_, err := w.Write([]byte{'\n'})
+		return err
+	}
+	return nil
+}
+
+// wrapLines copies p into dst, inserting a newline every wrap columns
+// given that the current line already holds col characters. It returns
+// the filled part of dst and the new column.
+func wrapLines(dst, p []byte, wrap, col int) ([]byte, int) {
+	n := 0
+	for len(p) > 0 {
+		k := copy(dst[n:n+min(wrap-col, len(p))], p)
+		n += k
+		p = p[k:]
+		col += k
+
+		if col == wrap {
+			dst[n] = '\n'
+			n++
+			col = 0
+		}
+	}
+	return dst[:n], col
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Decode reads encoded text from r until EOF and writes the decoded data
+// to w. Newlines are always skipped; if ignoreGarbage is true so is every
+// other character outside the encoding's alphabet.
+func Decode(w io.Writer, r io.Reader, enc Encoding, ignoreGarbage bool) error {
+	_, block := enc.Blocks()
+
+	// Characters to drop, looked up once per byte instead of asking the
+	// encoding each time.
+	var skip [256]bool
+	skip['\n'] = true
+	if ignoreGarbage {
+		for c := range skip {
+			skip[c] = skip[c] || !enc.Valid(byte(c))
+		}
+	}
+
+	// The input buffer has room for a partial block left over from the
+	// previous read, which is kept at its front.
+	buf := make([]byte, block+bufSize)
+	out := make([]byte, enc.DecodedLen(len(buf)))
+	pending := 0
+
+	for {
+		n, err := r.Read(buf[pending : pending+bufSize])
+
+		// Filter in place; the common case of a clean stream with only
+		// line breaks just shifts whole lines down.
+		p := buf[pending : pending+n]
+		k := pending
+		for len(p) > 0 {
+			i := bytes.IndexByte(p, '\n')
+			if i < 0 {
+				i = len(p)
+			}
+			if ignoreGarbage {
+				for _, c := range p[:i] {
+					if !skip[c] {
+						buf[k] = c
+						k++
+					}
+				}
+			} else {
+				k += copy(buf[k:], p[:i])
+			}
+			if i < len(p) {
+				i++
+			}
+			p = p[i:]
+		}
+
+		// Decode all complete blocks, keeping any remainder around
+		// until more input arrives.
+		if full := k / block * block; full > 0 {
+			m, e := enc.Decode(out, buf[:full])
+			if _, we := w.Write(out[:m]); we != nil {
+				return we
+			}
+			if e != nil {
+				return e
+			}
+			k = copy(buf, buf[full:k])
+		}
+		pending = k
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if pending > 0 {
+		m, err := enc.Decode(out, buf[:pending])
+		if _, we := w.Write(out[:m]); we != nil {
+			return we
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package codec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var vectors = []struct {
+	name string
+	enc  Encoding
+	in   string
+	out  string
+}{
+	{"base64", Base64, "foobar!", "Zm9vYmFyIQ=="},
+	{"base64url", Base64URL, "\xfb\xff", "-_8="},
+	{"base32", Base32, "foobar", "MZXW6YTBOI======"},
+	{"base32hex", Base32Hex, "foobar", "CPNMUOJ1E8======"},
+	{"base16", Base16, "\x00\xab\xff", "00ABFF"},
+	{"base2msbf", Base2MSBF, "\x01\x80", "0000000110000000"},
+	{"base2lsbf", Base2LSBF, "\x01\x80", "1000000000000001"},
+	{"z85", Z85, "\x86\x4f\xd2\x6f\xb5\x59\xf7\x5b", "HelloWorld"},
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, v := range vectors {
+		var buf bytes.Buffer
+		if err := Encode(&buf, strings.NewReader(v.in), v.enc, 0); err != nil {
+			t.Errorf("%s: Encode: %v", v.name, err)
+			continue
+		}
+		if buf.String() != v.out {
+			t.Errorf("%s: Encode = %q, want %q", v.name, buf.String(), v.out)
+		}
+
+		buf.Reset()
+		if err := Decode(&buf, strings.NewReader(v.out), v.enc, false); err != nil {
+			t.Errorf("%s: Decode: %v", v.name, err)
+			continue
+		}
+		if buf.String() != v.in {
+			t.Errorf("%s: Decode = %q, want %q", v.name, buf.String(), v.in)
+		}
+	}
+}
+
+// Round trip more than one buffer's worth of data so block boundaries and
+// line wrapping across reads get exercised.
+func TestRoundTripWrapped(t *testing.T) {
+	data := make([]byte, 3*bufSize+7)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for _, v := range vectors {
+		if v.enc == Z85 {
+			continue // needs a multiple of 4
+		}
+		var enc bytes.Buffer
+		if err := Encode(&enc, bytes.NewReader(data), v.enc, 76); err != nil {
+			t.Errorf("%s: Encode: %v", v.name, err)
+			continue
+		}
+		lines := strings.Split(strings.TrimSuffix(enc.String(), "\n"), "\n")
+		for i, l := range lines[:len(lines)-1] {
+			if len(l) != 76 {
+				t.Errorf("%s: line %d has %d columns", v.name, i, len(l))
+				break
+			}
+		}
+
+		var dec bytes.Buffer
+		if err := Decode(&dec, &enc, v.enc, false); err != nil {
+			t.Errorf("%s: Decode: %v", v.name, err)
+			continue
+		}
+		if !bytes.Equal(dec.Bytes(), data) {
+			t.Errorf("%s: round trip mismatch", v.name)
+		}
+	}
+}
+
+func TestDecodeGarbage(t *testing.T) {
+	const in = "Zm9v*YmFy\n"
+
+	var buf bytes.Buffer
+	if err := Decode(&buf, strings.NewReader(in), Base64, false); err != ErrInvalidInput {
+		t.Errorf("Decode without -i: err = %v, want %v", err, ErrInvalidInput)
+	}
+
+	buf.Reset()
+	if err := Decode(&buf, strings.NewReader(in), Base64, true); err != nil {
+		t.Errorf("Decode with -i: %v", err)
+	}
+	if buf.String() != "foobar" {
+		t.Errorf("Decode with -i = %q, want %q", buf.String(), "foobar")
+	}
+}
+
+// GNU keeps decoding after padding, so concatenated encodings decode to
+// the concatenated data.
+func TestDecodeAfterPadding(t *testing.T) {
+	for _, v := range []struct {
+		name string
+		enc  Encoding
+		in   string
+		out  string
+	}{
+		{"base64", Base64, "YQ==YQ==", "aa"},
+		{"base64 wrapped", Base64, "Zm8=\nYmFy\nYQ==\n", "fobara"},
+		{"base32", Base32, "ME======ME======", "aa"},
+	} {
+		var buf bytes.Buffer
+		if err := Decode(&buf, strings.NewReader(v.in), v.enc, false); err != nil {
+			t.Errorf("%s: Decode: %v", v.name, err)
+			continue
+		}
+		if buf.String() != v.out {
+			t.Errorf("%s: Decode = %q, want %q", v.name, buf.String(), v.out)
+		}
+	}
+}
+
+func TestBase16Case(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Decode(&buf, strings.NewReader("00abff"), Base16, false); err != ErrInvalidInput {
+		t.Errorf("Decode lower case: err = %v, want %v", err, ErrInvalidInput)
+	}
+}
+
+func TestZ85Length(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, strings.NewReader("abc"), Z85, 0); err != ErrInvalidInput {
+		t.Errorf("Encode: err = %v, want %v", err, ErrInvalidInput)
+	}
+}
@@ -0,0 +1,270 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+)
+
+// Encodings supported by basenc, by their option names.
+var (
+	Base64    Encoding = &stdEncoding{b64: base64.StdEncoding, alphabet: b64Alphabet}
+	Base64URL Encoding = &stdEncoding{b64: base64.URLEncoding, alphabet: b64URLAlphabet}
+	Base32    Encoding = &stdEncoding{b32: base32.StdEncoding, alphabet: b32Alphabet}
+	Base32Hex Encoding = &stdEncoding{b32: base32.HexEncoding, alphabet: b32HexAlphabet}
+	Base16    Encoding = base16{}
+	Base2MSBF Encoding = base2{msbFirst: true}
+	Base2LSBF Encoding = base2{msbFirst: false}
+	Z85       Encoding = z85{}
+)
+
+const (
+	b64Alphabet    = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	b64URLAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	b32Alphabet    = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	b32HexAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUV"
+	hexAlphabet    = "0123456789ABCDEF"
+	z85Alphabet    = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+)
+
+func contains(alphabet string, c byte) bool {
+	for i := 0; i < len(alphabet); i++ {
+		if alphabet[i] == c {
+			return true
+		}
+	}
+	return false
+}
+
+// stdEncoding adapts the padded base64 and base32 encodings from the
+// standard library.
+type stdEncoding struct {
+	b64      *base64.Encoding
+	b32      *base32.Encoding
+	alphabet string
+}
+
+func (e *stdEncoding) Blocks() (int, int) {
+	if e.b64 != nil {
+		return 3, 4
+	}
+	return 5, 8
+}
+
+func (e *stdEncoding) EncodedLen(n int) int {
+	if e.b64 != nil {
+		return e.b64.EncodedLen(n)
+	}
+	return e.b32.EncodedLen(n)
+}
+
+func (e *stdEncoding) DecodedLen(n int) int {
+	if e.b64 != nil {
+		return e.b64.DecodedLen(n)
+	}
+	return e.b32.DecodedLen(n)
+}
+
+func (e *stdEncoding) Encode(dst, src []byte) error {
+	if e.b64 != nil {
+		e.b64.Encode(dst, src)
+	} else {
+		e.b32.Encode(dst, src)
+	}
+	return nil
+}
+
+// Decode decodes src a padded run at a time. The standard library won't
+// take anything after padding, but GNU carries on decoding, so e.g.
+// "YQ==YQ==" is "aa".
+func (e *stdEncoding) Decode(dst, src []byte) (int, error) {
+	n := 0
+	for len(src) > 0 {
+		end := len(src)
+		if i := bytes.IndexByte(src, '='); i >= 0 {
+			for end = i; end < len(src) && src[end] == '='; end++ {
+			}
+		}
+
+		var (
+			m   int
+			err error
+		)
+		if e.b64 != nil {
+			m, err = e.b64.Decode(dst[n:], src[:end])
+		} else {
+			m, err = e.b32.Decode(dst[n:], src[:end])
+		}
+		n += m
+		if err != nil {
+			return n, ErrInvalidInput
+		}
+		src = src[end:]
+	}
+	return n, nil
+}
+
+func (e *stdEncoding) Valid(c byte) bool {
+	return c == '=' || contains(e.alphabet, c)
+}
+
+// base16 is upper case hexadecimal. Like GNU, lower case is invalid input.
+type base16 struct{}
+
+func (base16) Blocks() (int, int)   { return 1, 2 }
+func (base16) EncodedLen(n int) int { return n * 2 }
+func (base16) DecodedLen(n int) int { return n / 2 }
+func (base16) Valid(c byte) bool    { return unhex(c) >= 0 }
+
+func (base16) Encode(dst, src []byte) error {
+	for i, c := range src {
+		dst[i*2] = hexAlphabet[c>>4]
+		dst[i*2+1] = hexAlphabet[c&0x0f]
+	}
+	return nil
+}
+
+func (base16) Decode(dst, src []byte) (int, error) {
+	n := 0
+	for ; 2*n+2 <= len(src); n++ {
+		hi, lo := unhexTable[src[2*n]], unhexTable[src[2*n+1]]
+		if hi|lo > 0x0f {
+			return n, ErrInvalidInput
+		}
+		dst[n] = hi<<4 | lo
+	}
+	if len(src)%2 != 0 {
+		return n, ErrInvalidInput
+	}
+	return n, nil
+}
+
+// unhexTable maps a character to its hexadecimal value, or 0xff.
+var unhexTable [256]byte
+
+func unhex(c byte) int {
+	if v := unhexTable[c]; v != 0xff {
+		return int(v)
+	}
+	return -1
+}
+
+// base2 writes each byte as eight '0' or '1' characters, with either the
+// most or the least significant bit first.
+type base2 struct {
+	msbFirst bool
+}
+
+func (base2) Blocks() (int, int)   { return 1, 8 }
+func (base2) EncodedLen(n int) int { return n * 8 }
+func (base2) DecodedLen(n int) int { return n / 8 }
+func (base2) Valid(c byte) bool    { return c == '0' || c == '1' }
+
+// Each byte's expansion, precomputed for both bit orders.
+var base2Table [2][256][8]byte
+
+func (e base2) Encode(dst, src []byte) error {
+	table := &base2Table[0]
+	if e.msbFirst {
+		table = &base2Table[1]
+	}
+	for i, c := range src {
+		copy(dst[i*8:i*8+8], table[c][:])
+	}
+	return nil
+}
+
+func (e base2) Decode(dst, src []byte) (int, error) {
+	n := 0
+	for ; 8*n+8 <= len(src); n++ {
+		var c byte
+		for j := uint(0); j < 8; j++ {
+			bit := src[8*n+int(j)]
+			if bit != '0' && bit != '1' {
+				return n, ErrInvalidInput
+			}
+			shift := j
+			if e.msbFirst {
+				shift = 7 - j
+			}
+			c |= (bit - '0') << shift
+		}
+		dst[n] = c
+	}
+	if len(src)%8 != 0 {
+		return n, ErrInvalidInput
+	}
+	return n, nil
+}
+
+// z85 is ZeroMQ's Base85 (https://rfc.zeromq.org/spec/32/). It has no
+// padding, so input must be a multiple of 4 bytes.
+type z85 struct{}
+
+var z85Decode [256]byte
+
+func init() {
+	for i := range z85Decode {
+		z85Decode[i] = 0xff
+		unhexTable[i] = 0xff
+	}
+	for i := 0; i < len(z85Alphabet); i++ {
+		z85Decode[z85Alphabet[i]] = byte(i)
+	}
+	for i := 0; i < len(hexAlphabet); i++ {
+		unhexTable[hexAlphabet[i]] = byte(i)
+	}
+
+	for c := 0; c < 256; c++ {
+		for j := uint(0); j < 8; j++ {
+			base2Table[0][c][j] = '0' + byte(c>>j)&1
+			base2Table[1][c][j] = '0' + byte(c>>(7-j))&1
+		}
+	}
+}
+
+func (z85) Blocks() (int, int)   { return 4, 5 }
+func (z85) EncodedLen(n int) int { return (n + 3) / 4 * 5 }
+func (z85) DecodedLen(n int) int { return (n + 4) / 5 * 4 }
+func (z85) Valid(c byte) bool    { return z85Decode[c] != 0xff }
+
+func (z85) Encode(dst, src []byte) error {
+	if len(src)%4 != 0 {
+		return ErrInvalidInput
+	}
+	for ; len(src) > 0; src, dst = src[4:], dst[5:] {
+		v := uint32(src[0])<<24 | uint32(src[1])<<16 |
+			uint32(src[2])<<8 | uint32(src[3])
+		for i := 4; i >= 0; i-- {
+			dst[i] = z85Alphabet[v%85]
+			v /= 85
+		}
+	}
+	return nil
+}
+
+func (z85) Decode(dst, src []byte) (int, error) {
+	n := 0
+	for ; len(src) >= 5; src = src[5:] {
+		var v uint64
+		for i := 0; i < 5; i++ {
+			d := z85Decode[src[i]]
+			if d == 0xff {
+				return n, ErrInvalidInput
+			}
+			v = v*85 + uint64(d)
+		}
+		if v > 0xffffffff {
+			return n, ErrInvalidInput
+		}
+		dst[n] = byte(v >> 24)
+		dst[n+1] = byte(v >> 16)
+		dst[n+2] = byte(v >> 8)
+		dst[n+3] = byte(v)
+		n += 4
+	}
+	if len(src) != 0 {
+		return n, ErrInvalidInput
+	}
+	return n, nil
+}
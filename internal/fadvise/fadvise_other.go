@@ -0,0 +1,11 @@
+// +build !linux,!freebsd,!netbsd
+
+package fadvise
+
+import "os"
+
+// Sequential is a no-op: this system has no posix_fadvise.
+func Sequential(f *os.File) {}
+
+// DontNeed is a no-op: this system has no posix_fadvise.
+func DontNeed(f *os.File) {}
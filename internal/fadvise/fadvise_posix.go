@@ -0,0 +1,22 @@
+// +build linux freebsd netbsd
+
+package fadvise
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Sequential tells the kernel f will be read from start to end, so it
+// can read ahead more aggressively.
+func Sequential(f *os.File) {
+	unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_SEQUENTIAL)
+}
+
+// DontNeed tells the kernel f's cached pages won't be needed again, so
+// reading a large file once doesn't push everything else out of the page
+// cache.
+func DontNeed(f *os.File) {
+	unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_DONTNEED)
+}
@@ -0,0 +1,10 @@
+// +build !linux
+
+package noatime
+
+import "os"
+
+// Open is os.Open: this system has no O_NOATIME.
+func Open(name string) (*os.File, error) {
+	return os.Open(name)
+}
@@ -0,0 +1,262 @@
+/*
+	Go coreutils -- quote file names for output
+
+	Copyright (C) 2015 Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package quote implements GNU's quoting styles, used by ls
+// --quoting-style and for the names in diagnostics.
+package quote
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Style is a way of quoting a string.
+type Style int
+
+const (
+	Literal           Style = iota // output as is
+	Shell                          // quote for the shell if needed
+	ShellAlways                    // always quote for the shell
+	ShellEscape                    // like Shell, with $'' for unprintables
+	ShellEscapeAlways              // like ShellAlways, with $'' for unprintables
+	C                              // C string, "a\tb"
+	Escape                         // C string without the quotes
+	Locale                         // 'a\tb', GNU's quote()
+)
+
+var names = [...]string{
+	Literal:           "literal",
+	Shell:             "shell",
+	ShellAlways:       "shell-always",
+	ShellEscape:       "shell-escape",
+	ShellEscapeAlways: "shell-escape-always",
+	C:                 "c",
+	Escape:            "escape",
+	Locale:            "locale",
+}
+
+// Styles lists the names ParseStyle accepts, for error messages.
+var Styles = func() []string {
+	s := append([]string(nil), names[:]...)
+	return append(s, "clocale")
+}()
+
+func (s Style) String() string { return names[s] }
+
+// ParseStyle returns the style called name.
+func ParseStyle(name string) (Style, bool) {
+	if name == "clocale" {
+		return Locale, true
+	}
+	for i, n := range names {
+		if n == name {
+			return Style(i), true
+		}
+	}
+	return 0, false
+}
+
+// Default is the style used for names in diagnostics: the one named by
+// QUOTING_STYLE, or Locale if that's unset or invalid.
+var Default = func() Style {
+	if s, ok := ParseStyle(os.Getenv("QUOTING_STYLE")); ok {
+		return s
+	}
+	return Locale
+}()
+
+// Name quotes s in the Default style. It's meant for messages such as
+// "cannot touch 'foo'".
+func Name(s string) string {
+	return Quote(s, Default)
+}
+
+// File quotes s only if it needs quoting, for messages where the name
+// starts the line, as in "foo: No such file or directory". It's GNU's
+// quotef().
+func File(s string) string {
+	if Default == Locale {
+		return Quote(s, ShellEscape)
+	}
+	return Quote(s, Default)
+}
+
+// Quote returns s quoted in the given style.
+func Quote(s string, style Style) string {
+	switch style {
+	case Literal:
+		return s
+	case Shell, ShellAlways:
+		return shell(s, style == ShellAlways, false)
+	case ShellEscape, ShellEscapeAlways:
+		return shell(s, style == ShellEscapeAlways, true)
+	case C:
+		return `"` + escape(s, '"', false) + `"`
+	case Escape:
+		return escape(s, 0, true)
+	case Locale:
+		return "'" + escape(s, '\'', false) + "'"
+	}
+	panic("quote: invalid style " + strconv.Itoa(int(style)))
+}
+
+// escape backslash-escapes s like a C string literal. q is the quote
+// character in use, if any. With space set spaces are escaped too, so
+// the result can't be split by whitespace.
+func escape(s string, q byte, space bool) string {
+	var b []byte
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		c := s[i]
+		switch {
+		case c == '\\' || (q != 0 && c == q) || (space && c == ' '):
+			b = append(b, '\\', c)
+		case r == utf8.RuneError && n == 1, !unicode.IsPrint(r):
+			if e := cEscape(c); e != 0 && n == 1 {
+				b = append(b, '\\', e)
+			} else {
+				for _, c := range []byte(s[i : i+n]) {
+					b = appendOctal(b, c)
+				}
+			}
+		default:
+			b = append(b, s[i:i+n]...)
+		}
+		i += n
+	}
+	return string(b)
+}
+
+// cEscape returns the letter of the C escape for c, or 0 if it has none.
+func cEscape(c byte) byte {
+	switch c {
+	case '\a':
+		return 'a'
+	case '\b':
+		return 'b'
+	case '\f':
+		return 'f'
+	case '\n':
+		return 'n'
+	case '\r':
+		return 'r'
+	case '\t':
+		return 't'
+	case '\v':
+		return 'v'
+	}
+	return 0
+}
+
+func appendOctal(b []byte, c byte) []byte {
+	return append(b, '\\', '0'+c>>6, '0'+(c>>3)&7, '0'+c&7)
+}
+
+// shellSafe reports whether c never needs quoting for a POSIX shell.
+func shellSafe(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' ||
+		'0' <= c && c <= '9' || strings.IndexByte("%+,-./:=@_", c) >= 0
+}
+
+// shell quotes s with single quotes unless it's made up only of
+// characters that are safe everywhere (and isn't empty). With esc set,
+// unprintable characters are written as $'\n' segments between the
+// quoted parts.
+func shell(s string, always, esc bool) string {
+	if !always && s != "" {
+		safe := true
+		for i := 0; i < len(s) && safe; i++ {
+			c := s[i]
+			safe = shellSafe(c) || c >= utf8.RuneSelf ||
+				(i > 0 && (c == '~' || c == '#'))
+		}
+		if safe && (!esc || printable(s)) {
+			return s
+		}
+	}
+
+	// Like GNU, prefer "it's" to 'it'\''s' when nothing in s is special
+	// inside double quotes.
+	if strings.IndexByte(s, '\'') >= 0 && !strings.ContainsAny(s, "\"$`\\!") &&
+		(!esc || printable(s)) {
+		return `"` + s + `"`
+	}
+
+	var b []byte
+	open := false
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		if esc && (r == utf8.RuneError && n == 1 || !unicode.IsPrint(r)) {
+			if open {
+				b = append(b, '\'')
+				open = false
+			}
+			b = append(b, "$'"...)
+			for ; i < len(s); i += n {
+				r, n = utf8.DecodeRuneInString(s[i:])
+				if !(r == utf8.RuneError && n == 1 || !unicode.IsPrint(r)) {
+					break
+				}
+				if e := cEscape(s[i]); e != 0 && n == 1 {
+					b = append(b, '\\', e)
+				} else {
+					for _, c := range []byte(s[i : i+n]) {
+						b = appendOctal(b, c)
+					}
+				}
+			}
+			b = append(b, '\'')
+			continue
+		}
+
+		if s[i] == '\'' {
+			if open {
+				b = append(b, '\'')
+				open = false
+			}
+			b = append(b, `\'`...)
+		} else {
+			if !open {
+				b = append(b, '\'')
+				open = true
+			}
+			b = append(b, s[i:i+n]...)
+		}
+		i += n
+	}
+	if open {
+		b = append(b, '\'')
+	}
+	if len(b) == 0 {
+		return "''"
+	}
+	return string(b)
+}
+
+func printable(s string) bool {
+	for _, r := range s {
+		if r == utf8.RuneError || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
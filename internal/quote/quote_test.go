@@ -0,0 +1,60 @@
+package quote
+
+import "testing"
+
+var tests = []struct {
+	in    string
+	style Style
+	out   string
+}{
+	{"foo", Literal, "foo"},
+	{"a b\n", Literal, "a b\n"},
+
+	{"foo.txt", Shell, "foo.txt"},
+	{"", Shell, "''"},
+	{"a b", Shell, "'a b'"},
+	{"it's", Shell, `"it's"`},
+	{"it's $x", Shell, `'it'\''s $x'`},
+	{"~x", Shell, "'~x'"},
+	{"x~", Shell, "x~"},
+	{"foo", ShellAlways, "'foo'"},
+	{"'", ShellAlways, `"'"`},
+	{"'$", ShellAlways, `\''$'`},
+
+	{"a\nb", ShellEscape, `'a'$'\n''b'`},
+	{"\x01", ShellEscape, `$'\001'`},
+	{"ok", ShellEscape, "ok"},
+	{"ok", ShellEscapeAlways, "'ok'"},
+	{"héllo", ShellEscape, "héllo"},
+
+	{`a"b\`, C, `"a\"b\\"`},
+	{"tab\there", C, `"tab\there"`},
+	{"\xff", C, `"\377"`},
+	{"a b", Escape, `a\ b`},
+
+	{"foo", Locale, "'foo'"},
+	{"it's\n", Locale, `'it\'s\n'`},
+}
+
+func TestQuote(t *testing.T) {
+	for _, tt := range tests {
+		if got := Quote(tt.in, tt.style); got != tt.out {
+			t.Errorf("Quote(%q, %s) = %s, want %s", tt.in, tt.style, got, tt.out)
+		}
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	for _, name := range Styles {
+		s, ok := ParseStyle(name)
+		if !ok {
+			t.Errorf("ParseStyle(%q) failed", name)
+		}
+		if name != "clocale" && s.String() != name {
+			t.Errorf("ParseStyle(%q) = %s", name, s)
+		}
+	}
+	if _, ok := ParseStyle("bogus"); ok {
+		t.Error("ParseStyle accepted bogus")
+	}
+}
@@ -0,0 +1,10 @@
+package sig
+
+import "syscall"
+
+// Real-time signal range as glibc exposes it; it keeps the first two
+// for NPTL.
+const (
+	rtMin syscall.Signal = 34
+	rtMax syscall.Signal = 64
+)
@@ -0,0 +1,11 @@
+// +build !linux,!windows
+
+package sig
+
+import "syscall"
+
+// No real-time signals.
+const (
+	rtMin syscall.Signal = 0
+	rtMax syscall.Signal = 0
+)
@@ -0,0 +1,127 @@
+// +build !windows
+
+/*
+	Go coreutils -- signal names and numbers
+
+	Copyright (C) 2015 Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package sig converts between signal numbers and the names GNU
+// coreutils uses for them: the SIG-less form, e.g. "HUP", plus "RTMIN+n"
+// and "RTMAX-n" for real-time signals where the system has them.
+package sig
+
+import (
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Max is the highest signal number.
+var Max = func() syscall.Signal {
+	if rtMax > 0 {
+		return rtMax
+	}
+	max := syscall.Signal(0)
+	for s := syscall.Signal(1); s < 128; s++ {
+		if unix.SignalName(s) != "" {
+			max = s
+		}
+	}
+	return max
+}()
+
+// Name returns the name of s without its SIG prefix, or "" if s isn't
+// a valid signal.
+func Name(s syscall.Signal) string {
+	if name := unix.SignalName(s); name != "" {
+		return strings.TrimPrefix(name, "SIG")
+	}
+	if rtMin == 0 || s < rtMin || s > rtMax {
+		return ""
+	}
+
+	// Same split as glibc: the lower half counts up from RTMIN and the
+	// upper half down from RTMAX.
+	switch mid := (rtMin + rtMax) / 2; {
+	case s == rtMin:
+		return "RTMIN"
+	case s == rtMax:
+		return "RTMAX"
+	case s <= mid:
+		return "RTMIN+" + strconv.Itoa(int(s-rtMin))
+	default:
+		return "RTMAX-" + strconv.Itoa(int(rtMax-s))
+	}
+}
+
+// Describe returns a description of s, like strsignal(3).
+func Describe(s syscall.Signal) string {
+	if rtMin != 0 && s >= rtMin && s <= rtMax {
+		return "Real-time signal " + strconv.Itoa(int(s-rtMin))
+	}
+	desc := s.String()
+	if desc == "" {
+		return desc
+	}
+	return strings.ToUpper(desc[:1]) + desc[1:]
+}
+
+// Parse returns the signal named by s, which may be a number, or a name
+// with or without the SIG prefix. 0 is accepted, as kill(2) uses it to
+// check whether a process exists. ok is false if s isn't a signal.
+func Parse(s string) (sig syscall.Signal, ok bool) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 || syscall.Signal(n) > Max {
+			return 0, false
+		}
+		return syscall.Signal(n), true
+	}
+
+	name := strings.TrimPrefix(s, "SIG")
+	if name == "" {
+		return 0, false
+	}
+	if sig := unix.SignalNum("SIG" + name); sig != 0 {
+		return sig, true
+	}
+
+	if rtMin == 0 {
+		return 0, false
+	}
+	var base, dir syscall.Signal
+	switch {
+	case strings.HasPrefix(name, "RTMIN"):
+		base, dir, name = rtMin, 1, name[len("RTMIN"):]
+	case strings.HasPrefix(name, "RTMAX"):
+		base, dir, name = rtMax, -1, name[len("RTMAX"):]
+	default:
+		return 0, false
+	}
+	if name == "" {
+		return base, true
+	}
+	if (dir > 0 && name[0] != '+') || (dir < 0 && name[0] != '-') {
+		return 0, false
+	}
+	n, err := strconv.Atoi(name[1:])
+	if err != nil || n < 0 || n > int(rtMax-rtMin) {
+		return 0, false
+	}
+	return base + dir*syscall.Signal(n), true
+}
@@ -0,0 +1,48 @@
+/*
+	Go coreutils -- describe system errors the way strerror(3) does
+
+	Copyright (C) 2015 Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package strerror words errors from system calls as the C library does,
+// so diagnostics read like GNU's: "No such file or directory", not Go's
+// lower case "open foo: no such file or directory".
+package strerror
+
+import (
+	"os"
+	"strings"
+	"syscall"
+)
+
+// Text returns err's message the way strerror(3) words it. The file
+// name and operation an *os.PathError, *os.LinkError or *os.SyscallError
+// adds are dropped, since callers print their own.
+func Text(err error) string {
+	switch e := err.(type) {
+	case *os.PathError:
+		err = e.Err
+	case *os.LinkError:
+		err = e.Err
+	case *os.SyscallError:
+		err = e.Err
+	}
+	msg := err.Error()
+	if _, ok := err.(syscall.Errno); ok && msg != "" {
+		return strings.ToUpper(msg[:1]) + msg[1:]
+	}
+	return msg
+}
@@ -0,0 +1,25 @@
+package strerror
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	for _, v := range []struct {
+		err  error
+		want string
+	}{
+		{syscall.ENOENT, "No such file or directory"},
+		{&os.PathError{Op: "open", Path: "foo", Err: syscall.EACCES}, "Permission denied"},
+		{&os.LinkError{Op: "link", Old: "a", New: "b", Err: syscall.EEXIST}, "File exists"},
+		{os.NewSyscallError("kill", syscall.ESRCH), "No such process"},
+		{errors.New("invalid user"), "invalid user"},
+	} {
+		if got := Text(v.err); got != v.want {
+			t.Errorf("Text(%#v) = %q, want %q", v.err, got, v.want)
+		}
+	}
+}
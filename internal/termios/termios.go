@@ -0,0 +1,221 @@
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+/*
+	Go coreutils -- terminal attributes
+
+	Copyright (C) 2015 Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+// Package termios gets and sets terminal attributes the same way on
+// every system it supports. The flag word types, where the baud rate
+// lives and which modes exist all differ between Linux and the BSDs;
+// Termios hides the first two and modes a system lacks are 0 here.
+// Control characters a system lacks have an index of -1.
+package termios
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Termios holds a terminal's attributes.
+type Termios struct {
+	Iflag, Oflag, Cflag, Lflag uint64
+
+	// Cc holds the control characters, indexed by the V constants. An
+	// entry equal to Disabled is turned off.
+	Cc []byte
+
+	// Ispeed and Ospeed are in bits per second. An Ispeed of 0 means
+	// "the same as Ospeed".
+	Ispeed, Ospeed uint32
+
+	// Line is the line discipline, or -1 where there's no such thing.
+	Line int
+}
+
+// Get returns the attributes of the terminal open on fd.
+func Get(fd int) (*Termios, error) {
+	sys, err := unix.IoctlGetTermios(fd, reqGet)
+	if err != nil {
+		return nil, err
+	}
+	return fromSys(sys), nil
+}
+
+// Set changes the attributes of the terminal open on fd once its
+// pending output has been written, like tcsetattr(3)'s TCSADRAIN.
+func Set(fd int, t *Termios) error {
+	sys, err := unix.IoctlGetTermios(fd, reqGet)
+	if err != nil {
+		return err
+	}
+	if err := toSys(t, sys); err != nil {
+		return err
+	}
+	return unix.IoctlSetTermios(fd, reqSetDrain, sys)
+}
+
+// GetSize returns the window size of the terminal open on fd.
+func GetSize(fd int) (rows, cols int, err error) {
+	ws, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(ws.Row), int(ws.Col), nil
+}
+
+// SetSize sets the window size of the terminal open on fd.
+func SetSize(fd, rows, cols int) error {
+	ws, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+	if err != nil {
+		return err
+	}
+	ws.Row, ws.Col = uint16(rows), uint16(cols)
+	return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, ws)
+}
+
+// Save returns t as "stty -g" prints it: the four flag words and then
+// each control character, in hex, separated by colons. It's the same
+// string GNU stty prints on this system.
+func (t *Termios) Save() string {
+	flags := [...]uint64{t.Iflag, t.Oflag, saveCflag(t), t.Lflag}
+	var b []byte
+	for i, f := range flags {
+		if i > 0 {
+			b = append(b, ':')
+		}
+		b = strconv.AppendUint(b, f, 16)
+	}
+	for i := 0; i < nccs; i++ {
+		c := byte(0)
+		if i < len(t.Cc) {
+			c = t.Cc[i]
+		}
+		b = append(b, ':')
+		b = strconv.AppendUint(b, uint64(c), 16)
+	}
+	return string(b)
+}
+
+// Restore sets t from s, a string returned by Save. It reports whether s
+// was in that form; if not, t is unchanged.
+func (t *Termios) Restore(s string) bool {
+	fields := strings.Split(s, ":")
+	if len(fields) != 4+nccs {
+		return false
+	}
+	vals := make([]uint64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseUint(f, 16, 64)
+		if err != nil || (i >= 4 && v > 0xff) {
+			return false
+		}
+		vals[i] = v
+	}
+
+	t.Iflag, t.Oflag, t.Lflag = vals[0], vals[1], vals[3]
+	restoreCflag(t, vals[2])
+	for i := range t.Cc {
+		t.Cc[i] = byte(vals[4+i])
+	}
+	return true
+}
+
+// ValidSpeed reports whether the system supports a baud rate of n bits
+// per second.
+func ValidSpeed(n uint32) bool {
+	for _, s := range Speeds {
+		if s == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Modes every supported system has.
+const (
+	PARENB = unix.PARENB
+	PARODD = unix.PARODD
+	CSIZE  = unix.CSIZE
+	CS5    = unix.CS5
+	CS6    = unix.CS6
+	CS7    = unix.CS7
+	CS8    = unix.CS8
+	HUPCL  = unix.HUPCL
+	CSTOPB = unix.CSTOPB
+	CREAD  = unix.CREAD
+	CLOCAL = unix.CLOCAL
+
+	CRTSCTS = unix.CRTSCTS
+
+	IGNBRK  = unix.IGNBRK
+	BRKINT  = unix.BRKINT
+	IGNPAR  = unix.IGNPAR
+	PARMRK  = unix.PARMRK
+	INPCK   = unix.INPCK
+	ISTRIP  = unix.ISTRIP
+	INLCR   = unix.INLCR
+	IGNCR   = unix.IGNCR
+	ICRNL   = unix.ICRNL
+	IXON    = unix.IXON
+	IXOFF   = unix.IXOFF
+	IXANY   = unix.IXANY
+	IMAXBEL = unix.IMAXBEL
+
+	OPOST  = unix.OPOST
+	OCRNL  = unix.OCRNL
+	ONLCR  = unix.ONLCR
+	ONOCR  = unix.ONOCR
+	ONLRET = unix.ONLRET
+
+	ISIG    = unix.ISIG
+	ICANON  = unix.ICANON
+	IEXTEN  = unix.IEXTEN
+	ECHO    = unix.ECHO
+	ECHOE   = unix.ECHOE
+	ECHOK   = unix.ECHOK
+	ECHONL  = unix.ECHONL
+	NOFLSH  = unix.NOFLSH
+	TOSTOP  = unix.TOSTOP
+	ECHOPRT = unix.ECHOPRT
+	ECHOCTL = unix.ECHOCTL
+	ECHOKE  = unix.ECHOKE
+	FLUSHO  = unix.FLUSHO
+	EXTPROC = unix.EXTPROC
+)
+
+// Control characters every supported system has.
+const (
+	VINTR    = unix.VINTR
+	VQUIT    = unix.VQUIT
+	VERASE   = unix.VERASE
+	VKILL    = unix.VKILL
+	VEOF     = unix.VEOF
+	VEOL     = unix.VEOL
+	VEOL2    = unix.VEOL2
+	VSTART   = unix.VSTART
+	VSTOP    = unix.VSTOP
+	VSUSP    = unix.VSUSP
+	VREPRINT = unix.VREPRINT
+	VDISCARD = unix.VDISCARD
+	VWERASE  = unix.VWERASE
+	VLNEXT   = unix.VLNEXT
+	VMIN     = unix.VMIN
+	VTIME    = unix.VTIME
+)
@@ -0,0 +1,66 @@
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package termios
+
+import "golang.org/x/sys/unix"
+
+const (
+	reqGet      = unix.TIOCGETA
+	reqSetDrain = unix.TIOCSETAW
+)
+
+// Disabled turns a control character off (_POSIX_VDISABLE).
+const Disabled = 0xff
+
+const (
+	VSWTC   = -1
+	VDSUSP  = unix.VDSUSP
+	VSTATUS = unix.VSTATUS
+)
+
+const nccs = len(unix.Termios{}.Cc)
+
+// The BSDs keep baud rates as plain numbers.
+var Speeds = []uint32{
+	0, 50, 75, 110, 134, 150, 200, 300, 600, 1200, 1800, 2400, 4800,
+	7200, 9600, 14400, 19200, 28800, 38400, 57600, 76800, 115200, 230400,
+}
+
+func fromSys(sys *unix.Termios) *Termios {
+	return &Termios{
+		Iflag:  uint64(sys.Iflag),
+		Oflag:  uint64(sys.Oflag),
+		Cflag:  uint64(sys.Cflag),
+		Lflag:  uint64(sys.Lflag),
+		Cc:     append([]byte(nil), sys.Cc[:]...),
+		Ispeed: uint32(sys.Ispeed),
+		Ospeed: uint32(sys.Ospeed),
+		Line:   -1,
+	}
+}
+
+func toSys(t *Termios, sys *unix.Termios) error {
+	sys.Iflag = tcflag(t.Iflag)
+	sys.Oflag = tcflag(t.Oflag)
+	sys.Cflag = tcflag(t.Cflag)
+	sys.Lflag = tcflag(t.Lflag)
+	copy(sys.Cc[:], t.Cc)
+
+	// Unlike Linux, an input speed of 0 is taken literally.
+	in := t.Ispeed
+	if in == 0 {
+		in = t.Ospeed
+	}
+	sys.Ispeed = speed(in)
+	sys.Ospeed = speed(t.Ospeed)
+	return nil
+}
+
+// Like GNU, saved settings don't include the speeds here.
+func saveCflag(t *Termios) uint64 {
+	return t.Cflag
+}
+
+func restoreCflag(t *Termios, cflag uint64) {
+	t.Cflag = cflag
+}
@@ -0,0 +1,44 @@
+package termios
+
+import "golang.org/x/sys/unix"
+
+type (
+	tcflag = uint64
+	speed  = uint64
+)
+
+const (
+	CMSPAR = 0
+
+	IUCLC = 0
+	IUTF8 = unix.IUTF8
+
+	OLCUC = 0
+	OFILL = unix.OFILL
+	OFDEL = unix.OFDEL
+
+	NLDLY  = unix.NLDLY
+	NL0    = unix.NL0
+	NL1    = unix.NL1
+	CRDLY  = unix.CRDLY
+	CR0    = unix.CR0
+	CR1    = unix.CR1
+	CR2    = unix.CR2
+	CR3    = unix.CR3
+	TABDLY = unix.TABDLY
+	TAB0   = unix.TAB0
+	TAB1   = unix.TAB1
+	TAB2   = unix.TAB2
+	TAB3   = unix.TAB3
+	BSDLY  = unix.BSDLY
+	BS0    = unix.BS0
+	BS1    = unix.BS1
+	VTDLY  = unix.VTDLY
+	VT0    = unix.VT0
+	VT1    = unix.VT1
+	FFDLY  = unix.FFDLY
+	FF0    = unix.FF0
+	FF1    = unix.FF1
+
+	XCASE = 0
+)
@@ -0,0 +1,45 @@
+// +build dragonfly freebsd
+
+package termios
+
+type (
+	tcflag = uint32
+	speed  = uint32
+)
+
+// No output delays, case mapping or UTF-8 input mode here.
+const (
+	CMSPAR = 0
+
+	IUCLC = 0
+	IUTF8 = 0
+
+	OLCUC = 0
+	OFILL = 0
+	OFDEL = 0
+
+	NLDLY  = 0
+	NL0    = 0
+	NL1    = 0
+	CRDLY  = 0
+	CR0    = 0
+	CR1    = 0
+	CR2    = 0
+	CR3    = 0
+	TABDLY = 0
+	TAB0   = 0
+	TAB1   = 0
+	TAB2   = 0
+	TAB3   = 0
+	BSDLY  = 0
+	BS0    = 0
+	BS1    = 0
+	VTDLY  = 0
+	VT0    = 0
+	VT1    = 0
+	FFDLY  = 0
+	FF0    = 0
+	FF1    = 0
+
+	XCASE = 0
+)
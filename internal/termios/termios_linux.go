@@ -0,0 +1,156 @@
+package termios
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	reqGet      = unix.TCGETS
+	reqSetDrain = unix.TCSETSW
+)
+
+// Disabled turns a control character off (_POSIX_VDISABLE).
+const Disabled = 0
+
+const (
+	CMSPAR = unix.CMSPAR
+
+	IUCLC = unix.IUCLC
+	IUTF8 = unix.IUTF8
+
+	OLCUC = unix.OLCUC
+	OFILL = unix.OFILL
+	OFDEL = unix.OFDEL
+
+	NLDLY  = unix.NLDLY
+	NL0    = unix.NL0
+	NL1    = unix.NL1
+	CRDLY  = unix.CRDLY
+	CR0    = unix.CR0
+	CR1    = unix.CR1
+	CR2    = unix.CR2
+	CR3    = unix.CR3
+	TABDLY = unix.TABDLY
+	TAB0   = unix.TAB0
+	TAB1   = unix.TAB1
+	TAB2   = unix.TAB2
+	TAB3   = unix.TAB3
+	BSDLY  = unix.BSDLY
+	BS0    = unix.BS0
+	BS1    = unix.BS1
+	VTDLY  = unix.VTDLY
+	VT0    = unix.VT0
+	VT1    = unix.VT1
+	FFDLY  = unix.FFDLY
+	FF0    = unix.FF0
+	FF1    = unix.FF1
+
+	XCASE = unix.XCASE
+)
+
+const (
+	VSWTC   = unix.VSWTC
+	VDSUSP  = -1
+	VSTATUS = -1
+)
+
+// The number of control characters in glibc's struct termios, which is
+// what GNU's saved settings hold.
+const nccs = 32
+
+// Linux keeps the baud rates in Cflag as B constants, the input rate
+// shifted up by ibshift.
+const ibshift = 16
+
+var bauds = []struct {
+	speed uint32
+	code  uint32
+}{
+	{0, unix.B0}, {50, unix.B50}, {75, unix.B75}, {110, unix.B110},
+	{134, unix.B134}, {150, unix.B150}, {200, unix.B200},
+	{300, unix.B300}, {600, unix.B600}, {1200, unix.B1200},
+	{1800, unix.B1800}, {2400, unix.B2400}, {4800, unix.B4800},
+	{9600, unix.B9600}, {19200, unix.B19200}, {38400, unix.B38400},
+	{57600, unix.B57600}, {115200, unix.B115200},
+	{230400, unix.B230400}, {460800, unix.B460800},
+	{500000, unix.B500000}, {576000, unix.B576000},
+	{921600, unix.B921600}, {1000000, unix.B1000000},
+	{1152000, unix.B1152000}, {1500000, unix.B1500000},
+	{2000000, unix.B2000000}, {2500000, unix.B2500000},
+	{3000000, unix.B3000000}, {3500000, unix.B3500000},
+	{4000000, unix.B4000000},
+}
+
+// Speeds lists the supported baud rates, in increasing order.
+var Speeds = func() []uint32 {
+	s := make([]uint32, len(bauds))
+	for i, b := range bauds {
+		s[i] = b.speed
+	}
+	return s
+}()
+
+func speed(code uint32) uint32 {
+	for _, b := range bauds {
+		if b.code == code {
+			return b.speed
+		}
+	}
+	return 0
+}
+
+func code(speed uint32) (uint32, bool) {
+	for _, b := range bauds {
+		if b.speed == speed {
+			return b.code, true
+		}
+	}
+	return 0, false
+}
+
+func fromSys(sys *unix.Termios) *Termios {
+	return &Termios{
+		Iflag:  uint64(sys.Iflag),
+		Oflag:  uint64(sys.Oflag),
+		Cflag:  uint64(sys.Cflag &^ (unix.CBAUD | unix.CIBAUD)),
+		Lflag:  uint64(sys.Lflag),
+		Cc:     append([]byte(nil), sys.Cc[:]...),
+		Ospeed: speed(sys.Cflag & unix.CBAUD),
+		Ispeed: speed(sys.Cflag & unix.CIBAUD >> ibshift),
+		Line:   int(sys.Line),
+	}
+}
+
+func toSys(t *Termios, sys *unix.Termios) error {
+	out, ok := code(t.Ospeed)
+	if !ok {
+		return errors.New("invalid output speed")
+	}
+	in, ok := code(t.Ispeed)
+	if !ok {
+		return errors.New("invalid input speed")
+	}
+
+	sys.Iflag = uint32(t.Iflag)
+	sys.Oflag = uint32(t.Oflag)
+	sys.Lflag = uint32(t.Lflag)
+	sys.Cflag = uint32(t.Cflag)&^(unix.CBAUD|unix.CIBAUD) | out | in<<ibshift
+	copy(sys.Cc[:], t.Cc)
+	sys.Line = uint8(t.Line)
+	return nil
+}
+
+// Saved settings keep the speeds in Cflag, the way the kernel does.
+func saveCflag(t *Termios) uint64 {
+	out, _ := code(t.Ospeed)
+	in, _ := code(t.Ispeed)
+	return t.Cflag | uint64(out) | uint64(in)<<ibshift
+}
+
+func restoreCflag(t *Termios, cflag uint64) {
+	t.Ospeed = speed(uint32(cflag) & unix.CBAUD)
+	t.Ispeed = speed(uint32(cflag) & unix.CIBAUD >> ibshift)
+	t.Cflag = cflag &^ (unix.CBAUD | unix.CIBAUD)
+}
@@ -0,0 +1,36 @@
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package termios
+
+import "testing"
+
+func TestSaveRestore(t *testing.T) {
+	want := &Termios{
+		Iflag:  ICRNL | IXON,
+		Oflag:  OPOST | ONLCR,
+		Cflag:  CS8 | CREAD,
+		Lflag:  ISIG | ICANON | ECHO,
+		Cc:     make([]byte, nccs),
+		Ispeed: 9600,
+		Ospeed: 9600,
+	}
+	want.Cc[VINTR] = 3
+	want.Cc[VMIN] = 1
+
+	s := want.Save()
+	got := &Termios{Cc: make([]byte, len(want.Cc))}
+	if !got.Restore(s) {
+		t.Fatalf("Restore(%q) failed", s)
+	}
+	if got.Iflag != want.Iflag || got.Oflag != want.Oflag ||
+		got.Cflag != want.Cflag || got.Lflag != want.Lflag ||
+		string(got.Cc) != string(want.Cc) {
+		t.Errorf("Restore(%q) = %+v, want %+v", s, got, want)
+	}
+
+	for _, s := range []string{"", "1:2:3", s + ":0", "x" + s, s[:len(s)-1] + "100"} {
+		if got.Restore(s) {
+			t.Errorf("Restore(%q) succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// +build !windows
+
+/*
+	Go kill -- send a signal to a process
+
+	Copyright (C) 2015 Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+	Written by Eric Lagergren <ericscottlagergren@gmail.com>
+	Inspired by GNU's kill, which was written by Paul Eggert.
+*/
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/EricLagerg/go-coreutils/internal/sig"
+	"github.com/EricLagerg/go-coreutils/internal/strerror"
+)
+
+const (
+	Help = `Usage: kill [-s SIGNAL | -SIGNAL] PID...
+  or:  kill -l [SIGNAL]...
+  or:  kill -t [SIGNAL]...
+Send signals to processes, or list signals.
+
+Mandatory arguments to long options are mandatory for short options too.
+  -s, --signal=SIGNAL, -SIGNAL
+                   specify the name or number of the signal to be sent
+  -l, --list       list signal names, or convert signal names to/from numbers
+  -t, --table      print a table of signal information
+      --help     display this help and exit
+      --version  output version information and exit
+
+SIGNAL may be a signal name like 'HUP', or a signal number like '1',
+or the exit status of a process terminated by a signal.
+PID is an integer; if negative it identifies a process group.
+
+Report kill bugs to ericscottlagergren@gmail.com
+Go coreutils home page: <https://www.github.com/EricLagerg/go-coreutils/>
+`
+	Version = `kill (Go coreutils) 1.0
+Copyright (C) 2015 Eric Lagergren
+License GPLv3+: GNU GPL version 3 or later <http://gnu.org/licenses/gpl.html>.
+This is free software: you are free to change and redistribute it.
+There is NO WARRANTY, to the extent permitted by law.
+
+Written by Eric Lagergren <ericscottlagergren@gmail.com>
+`
+)
+
+var fatal = log.New(os.Stderr, "kill: ", 0)
+
+func usage(format string, a ...interface{}) {
+	fatal.Printf(format, a...)
+	fatal.Fatalln("Try 'kill --help' for more information.")
+}
+
+// operandSig parses a SIGNAL operand. Numbers above 128 are taken to be
+// the exit status of a process killed by a signal, as the shell reports
+// it.
+func operandSig(s string) (syscall.Signal, bool) {
+	if n, err := strconv.Atoi(s); err == nil && n > 128 && n < 256 {
+		s = strconv.Itoa(n - 128)
+	}
+	return sig.Parse(s)
+}
+
+// list prints the names of the signals in args, or the numbers of those
+// named, or every signal name if args is empty. With table set it prints
+// the number, name and description of each.
+func list(args []string, table bool) int {
+	if len(args) == 0 {
+		for s := syscall.Signal(1); s <= sig.Max; s++ {
+			if sig.Name(s) != "" {
+				args = append(args, strconv.Itoa(int(s)))
+			}
+		}
+	}
+
+	nameWidth := 0
+	if table {
+		for s := syscall.Signal(1); s <= sig.Max; s++ {
+			if n := len(sig.Name(s)); n > nameWidth {
+				nameWidth = n
+			}
+		}
+	}
+
+	status := 0
+	for _, arg := range args {
+		s, ok := operandSig(arg)
+		if !ok || (s == 0 && !table) || sig.Name(s) == "" && s != 0 {
+			fatal.Printf("'%s': invalid signal\n", arg)
+			status = 1
+			continue
+		}
+
+		switch {
+		case table:
+			name := sig.Name(s)
+			if s == 0 {
+				name = "0"
+			}
+			fmt.Printf("%2d %-*s %s\n", s, nameWidth, name, sig.Describe(s))
+		case arg[0] >= '0' && arg[0] <= '9':
+			fmt.Println(sig.Name(s))
+		default:
+			fmt.Println(int(s))
+		}
+	}
+	return status
+}
+
+// send sends s to each process (or process group, if negative) in pids.
+func send(s syscall.Signal, pids []string) int {
+	status := 0
+	for _, arg := range pids {
+		pid, err := strconv.Atoi(arg)
+		if err != nil {
+			fatal.Printf("'%s': invalid process id\n", arg)
+			status = 1
+			continue
+		}
+		if err := syscall.Kill(pid, s); err != nil {
+			fatal.Printf("(%d) - %s\n", pid, strerror.Text(err))
+			status = 1
+		}
+	}
+	return status
+}
+
+func main() {
+	var (
+		signal  = syscall.SIGTERM
+		sigSet  bool
+		listing bool
+		table   bool
+	)
+
+	setSig := func(name string) {
+		if sigSet {
+			usage("%s: multiple signals specified\n", name)
+		}
+		s, ok := sig.Parse(name)
+		if !ok {
+			fatal.Fatalf("'%s': invalid signal\n", name)
+		}
+		signal, sigSet = s, true
+	}
+
+	// pflag can't handle -SIGNAL or -9, so the arguments are parsed by
+	// hand. Like GNU, a number is only a signal when it's the first
+	// argument; after that it's a (negative) process group.
+	args := os.Args[1:]
+	for len(args) > 0 {
+		arg := args[0]
+		if len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+		args = args[1:]
+
+		if arg == "--" {
+			break
+		}
+
+		opt, val, hasVal := arg, "", false
+		if i := strings.IndexByte(arg, '='); i > 0 && strings.HasPrefix(arg, "--") {
+			opt, val, hasVal = arg[:i], arg[i+1:], true
+		}
+
+		switch opt {
+		case "--help":
+			fmt.Printf("%s", Help)
+			os.Exit(0)
+		case "--version":
+			fmt.Printf("%s", Version)
+			os.Exit(0)
+		case "-l", "--list":
+			listing = true
+			continue
+		case "-t", "--table":
+			table = true
+			continue
+		case "-s", "--signal":
+			if !hasVal {
+				if len(args) == 0 {
+					usage("option requires an argument -- '%s'\n", strings.TrimLeft(opt, "-"))
+				}
+				val, args = args[0], args[1:]
+			}
+			setSig(val)
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			usage("unrecognized option '%s'\n", arg)
+		case strings.HasPrefix(arg, "-s"):
+			setSig(arg[2:])
+		case arg[1] >= '0' && arg[1] <= '9' && len(os.Args)-len(args) > 2:
+			// Not the first argument: a process group.
+			args = append([]string{arg}, args...)
+			goto operands
+		default:
+			setSig(arg[1:])
+		}
+	}
+
+operands:
+	if listing || table {
+		if sigSet {
+			usage("cannot combine signal with -l or -t\n")
+		}
+		os.Exit(list(args, table))
+	}
+
+	if len(args) == 0 {
+		usage("no process ID specified\n")
+	}
+	os.Exit(send(signal, args))
+}
@@ -0,0 +1,127 @@
+/*
+	Go printenv -- print all or part of the environment
+
+	Copyright (C) 2015 Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+	Written by Eric Lagergren <ericscottlagergren@gmail.com>
+	Inspired by GNU's printenv, which was written by David MacKenzie and
+	Richard Mlynarik.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/EricLagerg/go-coreutils/internal/closeout"
+	flag "github.com/ogier/pflag"
+)
+
+const (
+	Help = `Usage: printenv [OPTION]... [VARIABLE]...
+Print the values of the specified environment VARIABLE(s).
+If no VARIABLE is specified, print name and value pairs for them all.
+
+  -0, --null     end each output line with NUL, not newline
+      --help     display this help and exit
+      --version  output version information and exit
+
+Report printenv bugs to ericscottlagergren@gmail.com
+Go coreutils home page: <https://www.github.com/EricLagerg/go-coreutils/>
+`
+	Version = `printenv (Go coreutils) 1.0
+Copyright (C) 2015 Eric Lagergren
+License GPLv3+: GNU GPL version 3 or later <http://gnu.org/licenses/gpl.html>.
+This is free software: you are free to change and redistribute it.
+There is NO WARRANTY, to the extent permitted by law.
+
+Written by Eric Lagergren <ericscottlagergren@gmail.com>
+`
+)
+
+// Exit statuses. Like GNU, usage errors get their own status so scripts
+// can tell them apart from unset variables.
+const (
+	exitUnset = 1
+	exitError = 2
+)
+
+var (
+	nullEol = flag.BoolP("null", "0", false, "")
+	help    = flag.Bool("help", false, "")
+	version = flag.Bool("version", false, "")
+
+	fatal = log.New(os.Stderr, "printenv: ", 0)
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s", Help)
+		os.Exit(exitError)
+	}
+	flag.Parse()
+
+	if *help {
+		fmt.Printf("%s", Help)
+		os.Exit(0)
+	}
+
+	if *version {
+		fmt.Printf("%s", Version)
+		os.Exit(0)
+	}
+
+	eol := byte('\n')
+	if *nullEol {
+		eol = 0
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	status := 0
+
+	if flag.NArg() == 0 {
+		for _, e := range os.Environ() {
+			out.WriteString(e)
+			out.WriteByte(eol)
+		}
+	} else {
+		for _, name := range flag.Args() {
+			// A name containing '=' can never match a variable.
+			val, ok := "", false
+			if !strings.Contains(name, "=") {
+				val, ok = os.LookupEnv(name)
+			}
+			if !ok {
+				status = exitUnset
+				continue
+			}
+			out.WriteString(val)
+			out.WriteByte(eol)
+		}
+	}
+
+	if err := closeout.Stdout(out); err != nil {
+		fatal.Println(err)
+		os.Exit(exitError)
+	}
+	os.Exit(status)
+}
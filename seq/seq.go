@@ -15,43 +15,188 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"io"
+	"log"
+	"math/big"
 	"os"
 	"strconv"
+	"strings"
+
+	"github.com/EricLagerg/go-coreutils/internal/closeout"
 )
-import "fmt"
+
+var fatal = log.New(os.Stderr, "seq: ", 0)
+
+func usage(msg string) {
+	fatal.Printf("%s\n", msg)
+	fatal.Fatalln("Try 'seq --help' for more information.")
+}
 
 func main() {
-	// TODO: Also handle floats.
-	start, inc, end := 1, 1, 0
-	nargs := len(os.Args)
-	switch nargs {
-	case 4:
-		inc = parseInt(os.Args[2])
-		fallthrough
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+
+	// Values are kept as exact rationals and each one is computed as
+	// first + i*step, so fractional steps don't drift and the last value
+	// is included exactly when it's reached.
+	first, step := big.NewRat(1, 1), big.NewRat(1, 1)
+	var last *big.Rat
+	prec := 0
+	switch len(args) {
 	case 3:
-		start = parseInt(os.Args[1])
+		step = parseNum(args[1])
+		if step.Sign() == 0 {
+			usage("invalid Zero increment value: '" + args[1] + "'")
+		}
+		prec = precision(args[1])
 		fallthrough
 	case 2:
-		end = parseInt(os.Args[nargs-1])
+		first = parseNum(args[0])
+		if p := precision(args[0]); p > prec {
+			prec = p
+		}
+		fallthrough
+	case 1:
+		last = parseNum(args[len(args)-1])
+	case 0:
+		usage("missing operand")
 	default:
-		msg := "missing operand"
-		if nargs > 4 {
-			msg = fmt.Sprintf("extra operand '%s'", os.Args[4])
+		usage("extra operand '" + args[3] + "'")
+	}
+
+	if prec == 0 && first.IsInt() && first.Sign() >= 0 && step.Cmp(one) == 0 {
+		// Div rounds down, since the denominator is positive; Quo would
+		// round "seq 0 -0.5" up to 0 and print it.
+		end := new(big.Int).Div(last.Num(), last.Denom())
+		if err := seqInts(os.Stdout, first.Num(), end); err != nil {
+			fatal.Fatalln(closeout.WriteError(err))
+		}
+		if err := closeout.Stdout(nil); err != nil {
+			fatal.Fatalln(err)
 		}
-		fmt.Println("seq:", msg)
-		os.Exit(1)
+		return
 	}
 
-	for i := start; i <= end; i += inc {
-		fmt.Println(i)
+	out := bufio.NewWriter(os.Stdout)
+	x, n := new(big.Rat), new(big.Rat)
+	for i := int64(0); ; i++ {
+		n.SetInt64(i)
+		x.Add(first, n.Mul(n, step))
+		if c := x.Cmp(last); c != 0 && c == step.Sign() {
+			break
+		}
+		out.WriteString(x.FloatString(prec))
+		if err := out.WriteByte('\n'); err != nil {
+			fatal.Fatalln(closeout.WriteError(err))
+		}
+	}
+	if err := closeout.Stdout(out); err != nil {
+		fatal.Fatalln(err)
 	}
 }
 
-func parseInt(a string) int {
-	i, err := strconv.Atoi(a)
-	if err != nil {
-		fmt.Println("seq:", err)
-		os.Exit(1)
+var one = big.NewRat(1, 1)
+
+const bufSize = 64 * 1024
+
+// seqInts writes the integers from first through last, one per line.
+// first must be non-negative. Rather than formatting every number it
+// keeps the current one as a decimal string and increments that in place,
+// which is what makes "seq 1000000000 | ..." fast.
+func seqInts(w io.Writer, first, last *big.Int) error {
+	if first.Cmp(last) > 0 {
+		return nil
+	}
+	num := []byte(first.String())
+	end := []byte(last.String())
+
+	buf := make([]byte, 0, bufSize)
+	for {
+		buf = append(buf, num...)
+		buf = append(buf, '\n')
+		if bytes.Equal(num, end) {
+			break
+		}
+		num = incr(num)
+
+		if len(buf)+len(num) >= bufSize {
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// incr adds one to the decimal number in b.
+func incr(b []byte) []byte {
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < '9' {
+			b[i]++
+			return b
+		}
+		b[i] = '0'
+	}
+	return append([]byte{'1'}, b...)
+}
+
+// parseNum parses a decimal number: an optional sign, digits with an
+// optional decimal point, and an optional exponent.
+func parseNum(s string) *big.Rat {
+	invalid := "invalid floating point argument: '" + s + "'"
+
+	mant, exp := s, ""
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mant, exp = s[:i], s[i+1:]
+		if _, err := strconv.Atoi(exp); err != nil {
+			usage(invalid)
+		}
+	}
+	if mant != "" && (mant[0] == '+' || mant[0] == '-') {
+		mant = mant[1:]
+	}
+	digits := 0
+	for i, dot := 0, false; i < len(mant); i++ {
+		switch {
+		case '0' <= mant[i] && mant[i] <= '9':
+			digits++
+		case mant[i] == '.' && !dot:
+			dot = true
+		default:
+			usage(invalid)
+		}
+	}
+	if digits == 0 {
+		usage(invalid)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		usage(invalid)
+	}
+	return r
+}
+
+// precision returns the number of digits after the decimal point needed
+// to print s, a number accepted by parseNum, exactly.
+func precision(s string) int {
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, _ = strconv.Atoi(s[i+1:])
+		s = s[:i]
+	}
+	p := 0
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		p = len(s) - i - 1
+	}
+	if p -= exp; p < 0 {
+		p = 0
 	}
-	return i
+	return p
 }
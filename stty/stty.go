@@ -0,0 +1,795 @@
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+/*
+	Go stty -- change and print terminal line settings
+
+	Copyright (C) 2015 Eric Lagergren
+
+	This program is free software: you can redistribute it and/or modify
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
+	This program is distributed in the hope that it will be useful,
+	but WITHOUT ANY WARRANTY; without even the implied warranty of
+	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+	GNU General Public License for more details.
+
+	You should have received a copy of the GNU General Public License
+	along with this program.  If not, see <http://www.gnu.org/licenses/>.
+*/
+
+/*
+	Written by Eric Lagergren <ericscottlagergren@gmail.com>
+	Inspired by GNU's stty, which was written by David MacKenzie.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/EricLagerg/go-coreutils/internal/closeout"
+	"github.com/EricLagerg/go-coreutils/internal/quote"
+	"github.com/EricLagerg/go-coreutils/internal/strerror"
+	"github.com/EricLagerg/go-coreutils/internal/termios"
+)
+
+const (
+	Help = `Usage: stty [-F DEVICE | --file=DEVICE] [SETTING]...
+  or:  stty [-F DEVICE | --file=DEVICE] [-a|--all]
+  or:  stty [-F DEVICE | --file=DEVICE] [-g|--save]
+Print or change terminal characteristics.
+
+Mandatory arguments to long options are mandatory for short options too.
+  -a, --all          print all current settings in human-readable form
+  -g, --save         print all current settings in a stty-readable form
+  -F, --file=DEVICE  open and use the specified DEVICE instead of stdin
+      --help     display this help and exit
+      --version  output version information and exit
+
+Optional - before SETTING indicates negation.  An * marks non-POSIX
+settings.  The underlying system defines which settings are available.
+
+Special characters:
+  eof CHAR      CHAR will send an end of file (terminate the input)
+  eol CHAR      CHAR will end the line
+* eol2 CHAR     alternate CHAR for ending the line
+  erase CHAR    CHAR will erase the last character typed
+  intr CHAR     CHAR will send an interrupt signal
+  kill CHAR     CHAR will erase the current line
+* lnext CHAR    CHAR will enter the next character quoted
+  quit CHAR     CHAR will send a quit signal
+* rprnt CHAR    CHAR will redraw the current line
+  start CHAR    CHAR will restart the output after stopping it
+  stop CHAR     CHAR will stop the output
+  susp CHAR     CHAR will send a terminal stop signal
+* werase CHAR   CHAR will erase the last word typed
+
+Special settings:
+   N             set the input and output speeds to N bauds
+   ispeed N      set the input speed to N
+ * line N        use line discipline N
+   min N         with -icanon, set N characters minimum for a completed read
+   ospeed N      set the output speed to N
+ * rows N        tell the kernel that the terminal has N rows
+ * cols N        tell the kernel that the terminal has N columns
+ * columns N     same as cols N
+ * size          print the number of rows and columns according to the kernel
+   speed         print the terminal speed
+   time N        with -icanon, set read timeout of N tenths of a second
+
+Control settings, input settings, output settings and local settings
+are the flags shown by stty -a; each may be preceded by - to turn it off.
+
+Combination settings:
+   cbreak        same as -icanon
+   -cbreak       same as icanon
+   cooked        same as brkint ignpar istrip icrnl ixon opost isig
+                 icanon
+   -cooked       same as raw
+ * ek            erase and kill characters to their default values
+   evenp         same as parenb -parodd cs7
+   -evenp        same as -parenb cs8
+ * litout        same as -parenb -istrip -opost cs8
+ * -litout       same as parenb istrip opost cs7
+ * nl            same as -icrnl -onlcr
+ * -nl           same as icrnl -inlcr -igncr onlcr -ocrnl -onlret
+   oddp          same as parenb parodd cs7
+   -oddp         same as -parenb cs8
+   parity        same as [-]evenp
+   pass8         same as -parenb -istrip cs8
+   -pass8        same as parenb istrip cs7
+   raw           same as -ignbrk -brkint -ignpar -parmrk -inpck -istrip
+                 -inlcr -igncr -icrnl -ixon -ixoff -icanon -opost
+                 -isig min 1 time 0
+   -raw          same as cooked
+   sane          same as cread -ignbrk brkint -inlcr -igncr icrnl
+                 icanon iexten echo echoe echok -echonl -noflsh
+                 -ixoff -tostop -echoprt echoctl echoke, all special
+                 characters to their default values
+ * tabs          same as tab0
+ * -tabs         same as tab3
+
+Handle the tty line connected to standard input.  Without arguments,
+prints baud rate, line discipline, and deviations from stty sane.  In
+settings, CHAR is taken literally, or coded as in ^c, 0x37, 0177 or
+127; special values ^- or undef used to disable special characters.
+
+Report stty bugs to ericscottlagergren@gmail.com
+Go coreutils home page: <https://www.github.com/EricLagerg/go-coreutils/>
+`
+	Version = `stty (Go coreutils) 1.0
+Copyright (C) 2015 Eric Lagergren
+License GPLv3+: GNU GPL version 3 or later <http://gnu.org/licenses/gpl.html>.
+This is free software: you are free to change and redistribute it.
+There is NO WARRANTY, to the extent permitted by law.
+
+Written by Eric Lagergren <ericscottlagergren@gmail.com>
+`
+)
+
+// Which flag word a mode lives in.
+type modeType int
+
+const (
+	control modeType = iota
+	input
+	output
+	local
+)
+
+// Mode flags.
+const (
+	saneSet   = 1 << iota // set by sane
+	saneUnset             // cleared by sane
+	rev                   // may be negated with a leading -
+	omit                  // an alias; left out of the display
+)
+
+type mode struct {
+	name  string
+	typ   modeType
+	flags int
+	bits  uint64
+	mask  uint64 // for modes that are one value of a multi-bit field
+}
+
+// Modes in the order they're displayed. Modes the system doesn't have
+// are zero and get skipped.
+var modes = []mode{
+	{"parenb", control, rev, termios.PARENB, 0},
+	{"parodd", control, rev, termios.PARODD, 0},
+	{"cmspar", control, rev, termios.CMSPAR, 0},
+	{"cs5", control, 0, termios.CS5, termios.CSIZE},
+	{"cs6", control, 0, termios.CS6, termios.CSIZE},
+	{"cs7", control, 0, termios.CS7, termios.CSIZE},
+	{"cs8", control, 0, termios.CS8, termios.CSIZE},
+	{"hupcl", control, rev, termios.HUPCL, 0},
+	{"hup", control, rev | omit, termios.HUPCL, 0},
+	{"cstopb", control, rev, termios.CSTOPB, 0},
+	{"cread", control, saneSet | rev, termios.CREAD, 0},
+	{"clocal", control, rev, termios.CLOCAL, 0},
+	{"crtscts", control, rev, termios.CRTSCTS, 0},
+
+	{"ignbrk", input, saneUnset | rev, termios.IGNBRK, 0},
+	{"brkint", input, saneSet | rev, termios.BRKINT, 0},
+	{"ignpar", input, rev, termios.IGNPAR, 0},
+	{"parmrk", input, rev, termios.PARMRK, 0},
+	{"inpck", input, rev, termios.INPCK, 0},
+	{"istrip", input, rev, termios.ISTRIP, 0},
+	{"inlcr", input, saneUnset | rev, termios.INLCR, 0},
+	{"igncr", input, saneUnset | rev, termios.IGNCR, 0},
+	{"icrnl", input, saneSet | rev, termios.ICRNL, 0},
+	{"ixon", input, rev, termios.IXON, 0},
+	{"ixoff", input, saneUnset | rev, termios.IXOFF, 0},
+	{"tandem", input, rev | omit, termios.IXOFF, 0},
+	{"iuclc", input, saneUnset | rev, termios.IUCLC, 0},
+	{"ixany", input, saneUnset | rev, termios.IXANY, 0},
+	{"imaxbel", input, saneSet | rev, termios.IMAXBEL, 0},
+	{"iutf8", input, saneUnset | rev, termios.IUTF8, 0},
+
+	{"opost", output, saneSet | rev, termios.OPOST, 0},
+	{"olcuc", output, saneUnset | rev, termios.OLCUC, 0},
+	{"ocrnl", output, saneUnset | rev, termios.OCRNL, 0},
+	{"onlcr", output, saneSet | rev, termios.ONLCR, 0},
+	{"onocr", output, saneUnset | rev, termios.ONOCR, 0},
+	{"onlret", output, saneUnset | rev, termios.ONLRET, 0},
+	{"ofill", output, saneUnset | rev, termios.OFILL, 0},
+	{"ofdel", output, saneUnset | rev, termios.OFDEL, 0},
+	{"nl1", output, saneUnset, termios.NL1, termios.NLDLY},
+	{"nl0", output, saneSet, termios.NL0, termios.NLDLY},
+	{"cr3", output, saneUnset, termios.CR3, termios.CRDLY},
+	{"cr2", output, saneUnset, termios.CR2, termios.CRDLY},
+	{"cr1", output, saneUnset, termios.CR1, termios.CRDLY},
+	{"cr0", output, saneSet, termios.CR0, termios.CRDLY},
+	{"tab3", output, saneUnset, termios.TAB3, termios.TABDLY},
+	{"tab2", output, saneUnset, termios.TAB2, termios.TABDLY},
+	{"tab1", output, saneUnset, termios.TAB1, termios.TABDLY},
+	{"tab0", output, saneSet, termios.TAB0, termios.TABDLY},
+	{"bs1", output, saneUnset, termios.BS1, termios.BSDLY},
+	{"bs0", output, saneSet, termios.BS0, termios.BSDLY},
+	{"vt1", output, saneUnset, termios.VT1, termios.VTDLY},
+	{"vt0", output, saneSet, termios.VT0, termios.VTDLY},
+	{"ff1", output, saneUnset, termios.FF1, termios.FFDLY},
+	{"ff0", output, saneSet, termios.FF0, termios.FFDLY},
+
+	{"isig", local, saneSet | rev, termios.ISIG, 0},
+	{"icanon", local, saneSet | rev, termios.ICANON, 0},
+	{"iexten", local, saneSet | rev, termios.IEXTEN, 0},
+	{"echo", local, saneSet | rev, termios.ECHO, 0},
+	{"echoe", local, saneSet | rev, termios.ECHOE, 0},
+	{"crterase", local, rev | omit, termios.ECHOE, 0},
+	{"echok", local, saneSet | rev, termios.ECHOK, 0},
+	{"echonl", local, saneUnset | rev, termios.ECHONL, 0},
+	{"noflsh", local, saneUnset | rev, termios.NOFLSH, 0},
+	{"xcase", local, saneUnset | rev, termios.XCASE, 0},
+	{"tostop", local, saneUnset | rev, termios.TOSTOP, 0},
+	{"echoprt", local, saneUnset | rev, termios.ECHOPRT, 0},
+	{"prterase", local, rev | omit, termios.ECHOPRT, 0},
+	{"echoctl", local, saneSet | rev, termios.ECHOCTL, 0},
+	{"ctlecho", local, rev | omit, termios.ECHOCTL, 0},
+	{"echoke", local, saneSet | rev, termios.ECHOKE, 0},
+	{"crtkill", local, rev | omit, termios.ECHOKE, 0},
+	{"flusho", local, saneUnset | rev, termios.FLUSHO, 0},
+	{"extproc", local, saneUnset | rev, termios.EXTPROC, 0},
+}
+
+// exists reports whether the system has m.
+func (m *mode) exists() bool {
+	return m.bits != 0 || m.mask != 0
+}
+
+func (m *mode) flag(t *termios.Termios) *uint64 {
+	switch m.typ {
+	case control:
+		return &t.Cflag
+	case input:
+		return &t.Iflag
+	case output:
+		return &t.Oflag
+	}
+	return &t.Lflag
+}
+
+// Control characters, in the order they're displayed, and their sane
+// values. Those the system doesn't have have an index of -1.
+var controls = []struct {
+	name  string
+	index int
+	sane  byte
+}{
+	{"intr", termios.VINTR, 'C' & 037},
+	{"quit", termios.VQUIT, '\\' & 037},
+	{"erase", termios.VERASE, 0177},
+	{"kill", termios.VKILL, 'U' & 037},
+	{"eof", termios.VEOF, 'D' & 037},
+	{"eol", termios.VEOL, termios.Disabled},
+	{"eol2", termios.VEOL2, termios.Disabled},
+	{"swtch", termios.VSWTC, termios.Disabled},
+	{"start", termios.VSTART, 'Q' & 037},
+	{"stop", termios.VSTOP, 'S' & 037},
+	{"susp", termios.VSUSP, 'Z' & 037},
+	{"dsusp", termios.VDSUSP, 'Y' & 037},
+	{"rprnt", termios.VREPRINT, 'R' & 037},
+	{"werase", termios.VWERASE, 'W' & 037},
+	{"lnext", termios.VLNEXT, 'V' & 037},
+	{"discard", termios.VDISCARD, 'O' & 037},
+	{"status", termios.VSTATUS, 'T' & 037},
+
+	// Not characters, but set and shown the same way.
+	{"min", termios.VMIN, 1},
+	{"time", termios.VTIME, 0},
+}
+
+var (
+	fatal = log.New(os.Stderr, "stty: ", 0)
+
+	out = bufio.NewWriter(os.Stdout)
+
+	// For wrapping long lines of settings, like GNU.
+	maxCol, curCol int
+)
+
+func usage(format string, a ...interface{}) {
+	fatal.Printf(format+"\n", a...)
+	fatal.Fatalln("Try 'stty --help' for more information.")
+}
+
+func main() {
+	var (
+		all, save bool
+		device    string
+		settings  []string
+	)
+
+	args := os.Args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-a" || arg == "--all":
+			all = true
+		case arg == "-g" || arg == "--save":
+			save = true
+		case arg == "-F" || arg == "--file":
+			if i+1 == len(args) {
+				usage("option requires an argument -- 'F'")
+			}
+			i++
+			device = args[i]
+		case strings.HasPrefix(arg, "--file="):
+			device = arg[len("--file="):]
+		case strings.HasPrefix(arg, "-F"):
+			device = arg[2:]
+		case arg == "--help":
+			fmt.Printf("%s", Help)
+			os.Exit(0)
+		case arg == "--version":
+			fmt.Printf("%s", Version)
+			os.Exit(0)
+		case arg == "--":
+		default:
+			// Settings like -echo look like options, so anything that
+			// isn't one of ours is left for the settings parser.
+			settings = append(settings, arg)
+		}
+	}
+
+	if all && save {
+		fatal.Fatalln("the options for verbose and stty-readable output styles are\nmutually exclusive")
+	}
+	if (all || save) && len(settings) > 0 {
+		fatal.Fatalln("when specifying an output style, modes may not be set")
+	}
+
+	fd, name := 0, "standard input"
+	if device != "" {
+		// Don't block waiting for carrier on a serial line. Fd puts the
+		// file back in blocking mode, and the deferred Close keeps it
+		// from being finalized, and fd closed, while it's still in use.
+		f, err := os.OpenFile(device, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+		if err != nil {
+			fatal.Fatalf("%s: %s\n", quote.File(device), strerror.Text(err))
+		}
+		defer f.Close()
+		fd, name = int(f.Fd()), device
+	}
+
+	t, err := termios.Get(fd)
+	if err != nil {
+		fatal.Fatalf("%s: %s\n", quote.File(name), strerror.Text(err))
+	}
+
+	maxCol = screenColumns()
+	switch {
+	case save:
+		fmt.Fprintln(out, t.Save())
+	case all:
+		display(fd, t, true)
+	case len(settings) == 0:
+		display(fd, t, false)
+	default:
+		set(fd, name, t, settings)
+	}
+
+	if err := closeout.Stdout(out); err != nil {
+		fatal.Fatalln(err)
+	}
+}
+
+// set applies settings, in order, to the terminal open on fd.
+func set(fd int, name string, t *termios.Termios, settings []string) {
+	orig := *t
+	orig.Cc = append([]byte(nil), t.Cc...)
+
+	// arg returns the argument of settings[i].
+	arg := func(i int) string {
+		if i+1 >= len(settings) {
+			usage("missing argument to %s", quote.Name(settings[i]))
+		}
+		return settings[i+1]
+	}
+
+	for i := 0; i < len(settings); i++ {
+		s := settings[i]
+		word := s
+		reversed := len(word) > 1 && word[0] == '-'
+		if reversed {
+			word = word[1:]
+		}
+
+		if setMode(t, word, reversed) || setCombination(t, word, reversed) {
+			continue
+		}
+		if reversed {
+			usage("invalid argument %s", quote.Name(s))
+		}
+
+		if setControl(t, word, &i, arg) {
+			continue
+		}
+
+		switch word {
+		case "ispeed":
+			t.Ispeed = speedArg(arg(i), "ispeed")
+			i++
+		case "ospeed":
+			t.Ospeed = speedArg(arg(i), "ospeed")
+			i++
+		case "rows", "cols", "columns":
+			n := intArg(arg(i), 0xffff)
+			i++
+			rows, cols, err := termios.GetSize(fd)
+			if err == nil {
+				if word == "rows" {
+					rows = n
+				} else {
+					cols = n
+				}
+				err = termios.SetSize(fd, rows, cols)
+			}
+			if err != nil {
+				fatal.Fatalf("%s: %s\n", quote.File(name), strerror.Text(err))
+			}
+		case "size":
+			rows, cols, err := termios.GetSize(fd)
+			if err != nil {
+				fatal.Fatalf("%s: %s\n", quote.File(name), strerror.Text(err))
+			}
+			fmt.Fprintf(out, "%d %d\n", rows, cols)
+		case "speed":
+			fmt.Fprintf(out, "%d\n", t.Ospeed)
+		case "line":
+			if t.Line < 0 {
+				usage("invalid argument %s", quote.Name(s))
+			}
+			t.Line = int(intArg(arg(i), 0xff))
+			i++
+		default:
+			if n, err := strconv.ParseUint(word, 10, 32); err == nil &&
+				termios.ValidSpeed(uint32(n)) {
+				t.Ispeed, t.Ospeed = uint32(n), uint32(n)
+				break
+			}
+			if !t.Restore(word) {
+				usage("invalid argument %s", quote.Name(s))
+			}
+		}
+	}
+
+	if equal(t, &orig) {
+		return
+	}
+	if err := termios.Set(fd, t); err != nil {
+		fatal.Fatalf("%s: %s\n", quote.File(name), strerror.Text(err))
+	}
+
+	// Like GNU, check that the driver took everything, since it's free
+	// to quietly ignore settings it doesn't support.
+	now, err := termios.Get(fd)
+	if err != nil {
+		fatal.Fatalf("%s: %s\n", quote.File(name), strerror.Text(err))
+	}
+	if !equal(now, t) {
+		fatal.Fatalf("%s: unable to perform all requested operations\n",
+			quote.File(name))
+	}
+}
+
+func equal(a, b *termios.Termios) bool {
+	ispeed := func(t *termios.Termios) uint32 {
+		if t.Ispeed == 0 {
+			return t.Ospeed
+		}
+		return t.Ispeed
+	}
+	return a.Iflag == b.Iflag && a.Oflag == b.Oflag &&
+		a.Cflag == b.Cflag && a.Lflag == b.Lflag &&
+		a.Ospeed == b.Ospeed && ispeed(a) == ispeed(b) &&
+		a.Line == b.Line && string(a.Cc) == string(b.Cc)
+}
+
+func setMode(t *termios.Termios, name string, reversed bool) bool {
+	for i := range modes {
+		m := &modes[i]
+		if m.name != name || !m.exists() {
+			continue
+		}
+		if reversed && m.flags&rev == 0 {
+			return false
+		}
+		p := m.flag(t)
+		if reversed {
+			*p &^= m.mask | m.bits
+		} else {
+			*p = *p&^m.mask | m.bits
+		}
+		return true
+	}
+	return false
+}
+
+func setCombination(t *termios.Termios, name string, reversed bool) bool {
+	switch name {
+	case "raw", "cooked":
+		if (name == "raw") == reversed {
+			t.Iflag |= termios.BRKINT | termios.IGNPAR | termios.ICRNL | termios.IXON
+			t.Oflag |= termios.OPOST
+			t.Lflag |= termios.ISIG | termios.ICANON
+		} else {
+			t.Iflag = 0
+			t.Oflag &^= termios.OPOST
+			t.Lflag &^= termios.ISIG | termios.ICANON | termios.XCASE
+			t.Cc[termios.VMIN] = 1
+			t.Cc[termios.VTIME] = 0
+		}
+	case "cbreak":
+		if reversed {
+			t.Lflag |= termios.ICANON
+		} else {
+			t.Lflag &^= termios.ICANON
+		}
+	case "nl":
+		if reversed {
+			t.Iflag |= termios.ICRNL
+			t.Iflag &^= termios.INLCR | termios.IGNCR
+			t.Oflag |= termios.ONLCR
+			t.Oflag &^= termios.OCRNL | termios.ONLRET
+		} else {
+			t.Iflag &^= termios.ICRNL
+			t.Oflag &^= termios.ONLCR
+		}
+	case "evenp", "parity", "oddp":
+		t.Cflag &^= termios.CSIZE
+		if reversed {
+			t.Cflag = t.Cflag&^termios.PARENB | termios.CS8
+		} else {
+			t.Cflag |= termios.PARENB | termios.CS7
+			if name == "oddp" {
+				t.Cflag |= termios.PARODD
+			} else {
+				t.Cflag &^= termios.PARODD
+			}
+		}
+	case "pass8", "litout":
+		t.Cflag &^= termios.CSIZE
+		if reversed {
+			t.Cflag |= termios.PARENB | termios.CS7
+			t.Iflag |= termios.ISTRIP
+			if name == "litout" {
+				t.Oflag |= termios.OPOST
+			}
+		} else {
+			t.Cflag = t.Cflag&^termios.PARENB | termios.CS8
+			t.Iflag &^= termios.ISTRIP
+			if name == "litout" {
+				t.Oflag &^= termios.OPOST
+			}
+		}
+	case "tabs":
+		if termios.TABDLY == 0 {
+			return false
+		}
+		t.Oflag &^= termios.TABDLY
+		if reversed {
+			t.Oflag |= termios.TAB3
+		} else {
+			t.Oflag |= termios.TAB0
+		}
+	case "ek":
+		if reversed {
+			return false
+		}
+		t.Cc[termios.VERASE] = 0177
+		t.Cc[termios.VKILL] = 'U' & 037
+	case "sane":
+		if reversed {
+			return false
+		}
+		sane(t)
+	default:
+		return false
+	}
+	return true
+}
+
+func sane(t *termios.Termios) {
+	for _, c := range controls {
+		// Some systems keep min and time in the eof and eol slots, which
+		// have just been set; like GNU, leave those alone.
+		if c.name == "min" && termios.VMIN == termios.VEOF ||
+			c.name == "time" && termios.VTIME == termios.VEOL {
+			continue
+		}
+		if c.index >= 0 {
+			t.Cc[c.index] = c.sane
+		}
+	}
+	for i := range modes {
+		m := &modes[i]
+		if !m.exists() {
+			continue
+		}
+		p := m.flag(t)
+		switch {
+		case m.flags&saneSet != 0:
+			*p = *p&^m.mask | m.bits
+		case m.flags&saneUnset != 0:
+			*p &^= m.mask | m.bits
+		}
+	}
+}
+
+// setControl handles "NAME CHAR" settings, advancing *i past CHAR.
+func setControl(t *termios.Termios, name string, i *int, arg func(int) string) bool {
+	for _, c := range controls {
+		if c.name != name || c.index < 0 {
+			continue
+		}
+		a := arg(*i)
+		*i++
+
+		var v byte
+		switch {
+		case name == "min" || name == "time":
+			v = byte(intArg(a, 0xff))
+		case a == "^-" || a == "undef":
+			v = termios.Disabled
+		case len(a) == 1:
+			v = a[0]
+		case len(a) == 2 && a[0] == '^':
+			if a[1] == '?' {
+				v = 0177
+			} else {
+				v = a[1] &^ 0140
+			}
+		default:
+			v = byte(intArg(a, 0xff))
+		}
+		t.Cc[c.index] = v
+		return true
+	}
+	return false
+}
+
+// intArg parses a number no greater than max, in decimal, or in octal or
+// hex with a 0 or 0x prefix.
+func intArg(s string, max uint64) int {
+	n, err := strconv.ParseUint(s, 0, 64)
+	if err != nil || n > max {
+		usage("invalid integer argument: %s", quote.Name(s))
+	}
+	return int(n)
+}
+
+func speedArg(s, which string) uint32 {
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || !termios.ValidSpeed(uint32(n)) {
+		usage("invalid %s %s", which, quote.Name(s))
+	}
+	return uint32(n)
+}
+
+// display prints the settings: all of them if all is set, otherwise only
+// those that differ from sane.
+func display(fd int, t *termios.Termios, all bool) {
+	if t.Ispeed == 0 || t.Ispeed == t.Ospeed {
+		wrapf("speed %d baud;", t.Ospeed)
+	} else {
+		wrapf("ispeed %d baud; ospeed %d baud;", t.Ispeed, t.Ospeed)
+	}
+	if all {
+		if rows, cols, err := termios.GetSize(fd); err == nil {
+			wrapf("rows %d; columns %d;", rows, cols)
+		}
+	}
+	if t.Line >= 0 {
+		wrapf("line = %d;", t.Line)
+	}
+	newline()
+
+	for _, c := range controls {
+		if c.index < 0 {
+			continue
+		}
+		v := t.Cc[c.index]
+		if c.name == "min" || c.name == "time" {
+			// Only meaningful in non-canonical mode.
+			if all || t.Lflag&termios.ICANON == 0 {
+				wrapf("%s = %d;", c.name, v)
+			}
+			continue
+		}
+		if all || v != c.sane {
+			wrapf("%s = %s;", c.name, visible(v))
+		}
+	}
+	newline()
+
+	prev := control
+	for i := range modes {
+		m := &modes[i]
+		if !m.exists() || m.flags&omit != 0 {
+			continue
+		}
+		if m.typ != prev {
+			newline()
+			prev = m.typ
+		}
+
+		mask := m.mask
+		if mask == 0 {
+			mask = m.bits
+		}
+		set := *m.flag(t)&mask == m.bits
+		switch {
+		case all && set, !all && set && m.flags&saneUnset != 0:
+			wrapf("%s", m.name)
+		case all && !set && m.flags&rev != 0,
+			!all && !set && m.flags&(saneSet|rev) == saneSet|rev:
+			wrapf("-%s", m.name)
+		}
+	}
+	newline()
+}
+
+// wrapf prints a setting, separated from the last one by a space or, if
+// it wouldn't fit on the line, a newline.
+func wrapf(format string, a ...interface{}) {
+	s := fmt.Sprintf(format, a...)
+	if curCol > 0 {
+		if maxCol-curCol < len(s) {
+			out.WriteByte('\n')
+			curCol = 0
+		} else {
+			out.WriteByte(' ')
+			curCol++
+		}
+	}
+	out.WriteString(s)
+	curCol += len(s)
+}
+
+func newline() {
+	if curCol > 0 {
+		out.WriteByte('\n')
+		curCol = 0
+	}
+}
+
+// screenColumns returns the width of standard output, or $COLUMNS, or 80.
+func screenColumns() int {
+	if _, cols, err := termios.GetSize(1); err == nil && cols > 0 {
+		return cols
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return 80
+}
+
+// visible returns c the way stty shows control characters: ^C, ^?, M-a
+// and so on.
+func visible(c byte) string {
+	if c == termios.Disabled {
+		return "<undef>"
+	}
+	var s string
+	if c >= 0200 {
+		s = "M-"
+		c -= 0200
+	}
+	switch {
+	case c < 040:
+		return s + "^" + string(c+0100)
+	case c == 0177:
+		return s + "^?"
+	}
+	return s + string(c)
+}
@@ -0,0 +1,278 @@
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/EricLagerg/go-coreutils/internal/termios"
+)
+
+// newTermios returns a zeroed Termios with room for every control
+// character.
+func newTermios() *termios.Termios {
+	return &termios.Termios{Cc: make([]byte, 32)}
+}
+
+func TestSetMode(t *testing.T) {
+	for _, v := range []struct {
+		setting string
+		in, out uint64 // the setting's flag word before and after
+		ok      bool
+	}{
+		{"echo", 0, termios.ECHO, true},
+		{"-echo", termios.ECHO | termios.ICANON, termios.ICANON, true},
+		{"icrnl", termios.IXON, termios.IXON | termios.ICRNL, true},
+		{"opost", 0, termios.OPOST, true},
+		{"cs7", termios.CS8 | termios.PARENB, termios.CS7 | termios.PARENB, true},
+		{"-cs7", termios.CS8, termios.CS8, false},
+		{"nosuch", 0, 0, false},
+	} {
+		tio := newTermios()
+		name := strings.TrimPrefix(v.setting, "-")
+		p := &tio.Lflag
+		for i := range modes {
+			if modes[i].name == name {
+				p = modes[i].flag(tio)
+			}
+		}
+		*p = v.in
+
+		ok := setMode(tio, name, name != v.setting)
+		if ok != v.ok || *p != v.out {
+			t.Errorf("setMode(%q) = %v, flags %#o, want %v, flags %#o",
+				v.setting, ok, *p, v.ok, v.out)
+		}
+	}
+}
+
+func TestSetCombination(t *testing.T) {
+	for _, v := range []struct {
+		setting string
+		in      termios.Termios
+		want    termios.Termios
+		ok      bool
+	}{
+		{
+			"raw",
+			termios.Termios{
+				Iflag: termios.BRKINT | termios.ICRNL,
+				Oflag: termios.OPOST | termios.ONLCR,
+				Lflag: termios.ISIG | termios.ICANON | termios.ECHO,
+			},
+			termios.Termios{Oflag: termios.ONLCR, Lflag: termios.ECHO},
+			true,
+		},
+		{
+			"-raw",
+			termios.Termios{},
+			termios.Termios{
+				Iflag: termios.BRKINT | termios.IGNPAR | termios.ICRNL | termios.IXON,
+				Oflag: termios.OPOST,
+				Lflag: termios.ISIG | termios.ICANON,
+			},
+			true,
+		},
+		{
+			"cbreak",
+			termios.Termios{Lflag: termios.ICANON | termios.ECHO},
+			termios.Termios{Lflag: termios.ECHO},
+			true,
+		},
+		{
+			"evenp",
+			termios.Termios{Cflag: termios.CS8 | termios.PARODD},
+			termios.Termios{Cflag: termios.CS7 | termios.PARENB},
+			true,
+		},
+		{
+			"oddp",
+			termios.Termios{Cflag: termios.CS8},
+			termios.Termios{Cflag: termios.CS7 | termios.PARENB | termios.PARODD},
+			true,
+		},
+		{
+			"-parity",
+			termios.Termios{Cflag: termios.CS7 | termios.PARENB},
+			termios.Termios{Cflag: termios.CS8},
+			true,
+		},
+		{
+			"nl",
+			termios.Termios{Iflag: termios.ICRNL, Oflag: termios.ONLCR},
+			termios.Termios{},
+			true,
+		},
+		{
+			"-nl",
+			termios.Termios{Iflag: termios.INLCR, Oflag: termios.OCRNL},
+			termios.Termios{Iflag: termios.ICRNL, Oflag: termios.ONLCR},
+			true,
+		},
+		{
+			"pass8",
+			termios.Termios{Iflag: termios.ISTRIP, Cflag: termios.CS7 | termios.PARENB},
+			termios.Termios{Cflag: termios.CS8},
+			true,
+		},
+		{"-ek", termios.Termios{}, termios.Termios{}, false},
+		{"-sane", termios.Termios{}, termios.Termios{}, false},
+		{"nosuch", termios.Termios{}, termios.Termios{}, false},
+	} {
+		// Only raw touches the control characters.
+		tio, want := &v.in, &v.want
+		tio.Cc, want.Cc = make([]byte, 32), make([]byte, 32)
+		if v.setting == "raw" {
+			want.Cc[termios.VMIN] = 1
+		}
+
+		name := strings.TrimPrefix(v.setting, "-")
+		ok := setCombination(tio, name, name != v.setting)
+		if ok != v.ok || !equal(tio, want) {
+			t.Errorf("setCombination(%q) = %v, %+v, want %v, %+v",
+				v.setting, ok, tio, v.ok, want)
+		}
+	}
+}
+
+func TestSane(t *testing.T) {
+	tio := newTermios()
+	tio.Cc[termios.VMIN] = 5
+	tio.Cc[termios.VTIME] = 3
+	tio.Lflag = termios.ECHONL | termios.TOSTOP
+	sane(tio)
+
+	for _, c := range controls {
+		if c.index >= 0 && tio.Cc[c.index] != c.sane {
+			t.Errorf("after sane, %s = %d, want %d", c.name, tio.Cc[c.index], c.sane)
+		}
+	}
+
+	var set, unset uint64 = termios.ICANON | termios.ECHO | termios.ISIG,
+		termios.ECHONL | termios.TOSTOP
+	if tio.Lflag&set != set {
+		t.Errorf("after sane, lflag %#o doesn't have %#o", tio.Lflag, set)
+	}
+	if tio.Lflag&unset != 0 {
+		t.Errorf("after sane, lflag %#o has %#o", tio.Lflag, tio.Lflag&unset)
+	}
+}
+
+func TestSetControl(t *testing.T) {
+	for _, v := range []struct {
+		name, arg string
+		index     int
+		want      byte
+		ok        bool
+	}{
+		{"intr", "^C", termios.VINTR, 3, true},
+		{"intr", "^c", termios.VINTR, 3, true},
+		{"erase", "^?", termios.VERASE, 0177, true},
+		{"erase", "x", termios.VERASE, 'x', true},
+		{"quit", "034", termios.VQUIT, 034, true},
+		{"kill", "0x15", termios.VKILL, 025, true},
+		{"eof", "undef", termios.VEOF, termios.Disabled, true},
+		{"eol", "^-", termios.VEOL, termios.Disabled, true},
+		{"min", "5", termios.VMIN, 5, true},
+		{"time", "10", termios.VTIME, 10, true},
+		{"nosuch", "1", -1, 0, false},
+	} {
+		tio := newTermios()
+		i := 0
+		ok := setControl(tio, v.name, &i, func(int) string { return v.arg })
+		if ok != v.ok {
+			t.Errorf("setControl(%q, %q) = %v, want %v", v.name, v.arg, ok, v.ok)
+			continue
+		}
+		if !ok {
+			if i != 0 {
+				t.Errorf("setControl(%q, %q) used an argument", v.name, v.arg)
+			}
+			continue
+		}
+		if i != 1 {
+			t.Errorf("setControl(%q, %q) advanced by %d, want 1", v.name, v.arg, i)
+		}
+		if got := tio.Cc[v.index]; got != v.want {
+			t.Errorf("setControl(%q, %q) set %d, want %d", v.name, v.arg, got, v.want)
+		}
+	}
+}
+
+func TestVisible(t *testing.T) {
+	for _, v := range []struct {
+		c    byte
+		want string
+	}{
+		{3, "^C"},
+		{034, "^\\"},
+		{0177, "^?"},
+		{'a', "a"},
+		{0200 + 'a', "M-a"},
+		{0201, "M-^A"},
+		{termios.Disabled, "<undef>"},
+	} {
+		if got := visible(v.c); got != v.want {
+			t.Errorf("visible(%#o) = %q, want %q", v.c, got, v.want)
+		}
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	defer func(w *bufio.Writer, max int) {
+		out, maxCol, curCol = w, max, 0
+	}(out, maxCol)
+
+	for _, v := range []struct {
+		name   string
+		width  int
+		change func(*termios.Termios)
+		want   string
+	}{
+		{
+			"sane", 80,
+			func(*termios.Termios) {},
+			"speed 38400 baud; line = 0;\n",
+		},
+		{
+			"split speeds", 80,
+			func(t *termios.Termios) { t.Ispeed = 9600 },
+			"ispeed 9600 baud; ospeed 38400 baud; line = 0;\n",
+		},
+		{
+			"changes", 80,
+			func(t *termios.Termios) {
+				t.Cc[termios.VINTR] = termios.Disabled
+				t.Lflag &^= termios.ECHO
+				t.Lflag |= termios.TOSTOP
+			},
+			"speed 38400 baud; line = 0;\nintr = <undef>;\n-echo tostop\n",
+		},
+		{
+			"non-canonical", 80,
+			func(t *termios.Termios) { t.Lflag &^= termios.ICANON },
+			"speed 38400 baud; line = 0;\nmin = 1; time = 0;\n-icanon\n",
+		},
+		{
+			"wrapped", 20,
+			func(*termios.Termios) {},
+			"speed 38400 baud;\nline = 0;\n",
+		},
+	} {
+		tio := newTermios()
+		sane(tio)
+		tio.Ospeed, tio.Line = 38400, 0
+		v.change(tio)
+
+		var buf bytes.Buffer
+		out, maxCol, curCol = bufio.NewWriter(&buf), v.width, 0
+		display(-1, tio, false)
+		out.Flush()
+		if buf.String() != v.want {
+			t.Errorf("%s: display printed\n%q, want\n%q", v.name, buf.String(), v.want)
+		}
+	}
+}
@@ -14,28 +14,217 @@
 
 package main
 
-import "os"
-import "fmt"
-import "io/ioutil"
-import flag "github.com/ogier/pflag"
-import "log"
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/EricLagerg/go-coreutils/internal/closeout"
+	"github.com/EricLagerg/go-coreutils/internal/quote"
+	flag "github.com/ogier/pflag"
+)
+
+const (
+	Help = `Usage: tee [OPTION]... [FILE]...
+Copy standard input to each FILE, and also to standard output.
+
+  -a, --append              append to the given FILEs, do not overwrite
+  -i, --ignore-interrupts   ignore interrupt signals
+  -p                        operate in a more appropriate MODE with pipes
+      --output-error[=MODE]  set behavior on write error.  See MODE below
+      --help     display this help and exit
+      --version  output version information and exit
+
+MODE determines behavior with write errors on the outputs:
+  warn           diagnose errors writing to any output
+  warn-nopipe    diagnose errors writing to any output not a pipe
+  exit           exit on error writing to any output
+  exit-nopipe    exit on error writing to any output not a pipe
+The default MODE for the -p option is 'warn-nopipe'.
+With "nopipe" MODEs, exit immediately if all outputs become broken pipes.
+The default operation when --output-error is not specified, is to
+exit immediately on error writing to a pipe, and diagnose errors
+writing to non pipe outputs.
+
+Go coreutils home page: <https://www.github.com/EricLagerg/go-coreutils/>
+`
+	Version = `tee (Go coreutils) 2.0
+License GPLv3: GNU GPL version 3 <http://gnu.org/licenses/gpl.html>.
+This is free software: you are free to change and redistribute it.
+There is NO WARRANTY, to the extent permitted by law.
+`
+)
+
+// What to do when writing to an output fails.
+const (
+	outputSigpipe    = "" // the default: die on SIGPIPE, warn otherwise
+	outputWarn       = "warn"
+	outputWarnNopipe = "warn-nopipe"
+	outputExit       = "exit"
+	outputExitNopipe = "exit-nopipe"
+)
+
+var (
+	appendMode  = flag.BoolP("append", "a", false, "append to the given files")
+	ignoreInt   = flag.BoolP("ignore-interrupts", "i", false, "ignore interrupt signals")
+	pipeMode    = flag.BoolP("N1O1L1O1N1G1O1P1T1", "p", false, "operate in a more appropriate mode with pipes")
+	outputError = flag.String("output-error", outputSigpipe, "set behavior on write error")
+	help        = flag.Bool("help", false, "print help")
+	version     = flag.Bool("version", false, "print program's version")
+
+	fatal = log.New(os.Stderr, "tee: ", 0)
+)
+
+type output struct {
+	name string
+	w    io.WriteCloser
+}
 
 func main() {
-	flagAppend := flag.BoolP("append", "a", false, "append to file")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s", Help)
+		os.Exit(1)
+	}
+
+	// MODE is optional, which the flag parser can't express, so give a
+	// bare --output-error its default up front.
+	for i, arg := range os.Args[1:] {
+		if arg == "--" {
+			break
+		}
+		if arg == "--output-error" {
+			os.Args[i+1] = "--output-error=" + outputWarnNopipe
+		}
+	}
 	flag.Parse()
-	bytes, _ := ioutil.ReadAll(os.Stdin)
-	for i := 0; i < len(flag.Args()); i++ {
-		if *flagAppend {
-			f, err := os.OpenFile(flag.Args()[i], os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
-			if err != nil {log.Fatal(err)}
-			f.Write(bytes)
-			f.Close()
-		} else {
-			f, err := os.OpenFile(flag.Args()[i], os.O_WRONLY|os.O_CREATE, 0644)
-			if err != nil {log.Fatal(err)}
-			f.Write(bytes)
-			f.Close()
-		}
-	}
-	fmt.Printf("%s", string(bytes))
+
+	if *help {
+		fmt.Printf("%s", Help)
+		os.Exit(0)
+	}
+
+	if *version {
+		fmt.Printf("%s", Version)
+		os.Exit(0)
+	}
+
+	mode := *outputError
+	if *pipeMode && mode == outputSigpipe {
+		mode = outputWarnNopipe
+	}
+	switch mode {
+	case outputSigpipe:
+	case outputWarn, outputWarnNopipe, outputExit, outputExitNopipe:
+		// Take write errors on pipes as EPIPE instead of dying.
+		signal.Ignore(syscall.SIGPIPE)
+	default:
+		fatal.Printf("invalid argument %s for '--output-error'\n", quote.Name(mode))
+		fmt.Fprintf(os.Stderr, "Valid arguments are:\n  - 'warn'\n  - 'warn-nopipe'\n  - 'exit'\n  - 'exit-nopipe'\n")
+		fatal.Fatalln("Try 'tee --help' for more information.")
+	}
+
+	if *ignoreInt {
+		signal.Ignore(os.Interrupt)
+	}
+
+	if !tee(flag.Args(), mode) {
+		os.Exit(1)
+	}
+}
+
+// tee copies standard input to standard output and to each file in
+// names as it's read. It returns false if anything went wrong.
+func tee(names []string, mode string) bool {
+	ok := true
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if *appendMode {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	outs := []*output{{"standard output", os.Stdout}}
+	for _, name := range names {
+		f, err := os.OpenFile(name, flags, 0666)
+		if err != nil {
+			fatal.Printf("%s: %v\n", quote.File(name), pathErr(err))
+			ok = false
+			continue
+		}
+		outs = append(outs, &output{name, f})
+	}
+	live := len(outs)
+
+	buf := make([]byte, 64*1024)
+	for live > 0 {
+		n, err := os.Stdin.Read(buf)
+		if n > 0 {
+			for _, o := range outs {
+				if o.w == nil {
+					continue
+				}
+				if _, werr := o.w.Write(buf[:n]); werr != nil {
+					// By default a broken pipe ends tee as SIGPIPE would,
+					// but the runtime only raises it for standard output.
+					epipe := pathErr(werr) == syscall.EPIPE
+					if epipe && mode == outputSigpipe {
+						closeout.DieOfSIGPIPE()
+					}
+
+					// Like GNU, a broken pipe is only worth mentioning
+					// in the modes that don't excuse pipes.
+					fail := !epipe ||
+						mode == outputWarn || mode == outputExit
+					if fail {
+						fatal.Printf("%s: %v\n", quote.File(o.name), pathErr(werr))
+						if mode == outputExit || mode == outputExitNopipe {
+							os.Exit(1)
+						}
+						ok = false
+					}
+					if o.w != os.Stdout {
+						o.w.Close()
+					}
+					o.w = nil
+					live--
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fatal.Printf("read error: %v\n", pathErr(err))
+			ok = false
+			break
+		}
+	}
+
+	for _, o := range outs[1:] {
+		if o.w == nil {
+			continue
+		}
+		if err := o.w.Close(); err != nil {
+			fatal.Printf("%s: %v\n", quote.File(o.name), pathErr(err))
+			ok = false
+		}
+	}
+	if outs[0].w != nil {
+		if err := closeout.Stdout(nil); err != nil {
+			fatal.Println(err)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// pathErr strips the *os.PathError wrapping so diagnostics don't repeat
+// the file name.
+func pathErr(err error) error {
+	if e, ok := err.(*os.PathError); ok {
+		return e.Err
+	}
+	return err
 }
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts tried, in order, against the entire -d string before falling
+// back to parsing it item by item.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999 -07:00",
+	"2006-01-02 15:04:05.999999999 MST",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC822Z,
+	time.RFC822,
+	time.UnixDate,
+	time.RubyDate,
+	time.ANSIC,
+	"Mon Jan _2 15:04:05 MST 2006",
+	"Jan _2 2006 15:04:05",
+	"Jan _2 2006",
+	"Jan _2, 2006",
+	"_2 Jan 2006",
+	"01/02/2006 15:04:05",
+	"01/02/2006",
+}
+
+// Units for relative items, e.g. "3 days ago".
+var relUnits = map[string]struct {
+	years, months, days int
+	dur                 time.Duration
+}{
+	"year":      {years: 1},
+	"month":     {months: 1},
+	"fortnight": {days: 14},
+	"week":      {days: 7},
+	"day":       {days: 1},
+	"hour":      {dur: time.Hour},
+	"minute":    {dur: time.Minute},
+	"min":       {dur: time.Minute},
+	"second":    {dur: time.Second},
+	"sec":       {dur: time.Second},
+}
+
+// getDate parses the -d argument relative to now. It understands the most
+// common forms GNU's getdate does: "@SECONDS", ISO 8601 dates and times,
+// the formats date(1) itself prints, the words now/today/yesterday/tomorrow
+// and relative items such as "2 hours ago" or "next week".
+func getDate(s string, now time.Time) (time.Time, error) {
+	invalid := fmt.Errorf("invalid date format '%s'", s)
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return now, nil
+	}
+
+	if s[0] == '@' {
+		sec, nsec, ok := parseSeconds(s[1:], true)
+		if !ok {
+			return time.Time{}, invalid
+		}
+		return time.Unix(sec, nsec), nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	var (
+		loc      = time.Local
+		date     time.Time
+		haveDate bool
+		haveTime bool
+		hour     int
+		min      int
+		sec      int
+		nsec     int
+
+		years, months, days int
+		dur                 time.Duration
+	)
+
+	fields := strings.Fields(strings.ToLower(s))
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		switch f {
+		case "now", "today":
+			continue
+		case "yesterday":
+			days--
+			continue
+		case "tomorrow":
+			days++
+			continue
+		case "utc", "gmt", "z":
+			loc = time.UTC
+			continue
+		}
+
+		if t, err := time.Parse("2006-01-02", f); err == nil && !haveDate {
+			date, haveDate = t, true
+			continue
+		}
+
+		if h, m, sc, ns, ok := parseClock(f); ok && !haveTime {
+			hour, min, sec, nsec, haveTime = h, m, sc, ns, true
+			if i+1 < len(fields) {
+				switch fields[i+1] {
+				case "am":
+					if hour == 12 {
+						hour = 0
+					}
+					i++
+				case "pm":
+					if hour < 12 {
+						hour += 12
+					}
+					i++
+				}
+			}
+			continue
+		}
+
+		// Relative item: [+-]N UNIT, "next UNIT", "last UNIT" or plain
+		// UNIT, optionally followed by "ago".
+		n := 1
+		switch f {
+		case "next":
+			i++
+		case "last":
+			n = -1
+			i++
+		default:
+			if v, err := strconv.Atoi(f); err == nil {
+				n = v
+				i++
+			} else if len(f) > 1 && (f[0] == '+' || f[0] == '-') {
+				// "-1day" style, with no space before the unit.
+				j := 1
+				for j < len(f) && f[j] >= '0' && f[j] <= '9' {
+					j++
+				}
+				v, err := strconv.Atoi(f[:j])
+				if err != nil || j == len(f) {
+					return time.Time{}, invalid
+				}
+				n = v
+				fields[i] = f[j:]
+			}
+		}
+		if i >= len(fields) {
+			return time.Time{}, invalid
+		}
+
+		unit, ok := relUnits[strings.TrimSuffix(fields[i], "s")]
+		if !ok {
+			return time.Time{}, invalid
+		}
+		if i+1 < len(fields) && fields[i+1] == "ago" {
+			n = -n
+			i++
+		}
+
+		years += n * unit.years
+		months += n * unit.months
+		days += n * unit.days
+		dur += time.Duration(n) * unit.dur
+	}
+
+	t := now.In(loc)
+	if haveDate {
+		// date was parsed as UTC, so copy its fields rather than convert.
+		t = time.Date(date.Year(), date.Month(), date.Day(),
+			t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	}
+	if haveTime {
+		t = time.Date(t.Year(), t.Month(), t.Day(), hour, min, sec, nsec, loc)
+	} else if haveDate {
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+	return t.AddDate(years, months, days).Add(dur), nil
+}
+
+// parseClock parses HH:MM[:SS[.NNN]].
+func parseClock(s string) (hour, min, sec, nsec int, ok bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, 0, 0, 0, false
+	}
+
+	var err error
+	if !isDigits(parts[0]) || !isDigits(parts[1]) {
+		return 0, 0, 0, 0, false
+	}
+	if hour, err = strconv.Atoi(parts[0]); err != nil || hour > 23 {
+		return 0, 0, 0, 0, false
+	}
+	if min, err = strconv.Atoi(parts[1]); err != nil || min > 59 {
+		return 0, 0, 0, 0, false
+	}
+	if len(parts) == 3 {
+		s, ns, ok := parseSeconds(parts[2], false)
+		if !ok || s >= 61 {
+			return 0, 0, 0, 0, false
+		}
+		sec, nsec = int(s), int(ns)
+	}
+	return hour, min, sec, nsec, true
+}
+
+// parseSeconds parses SECONDS[.FRACTION], with a leading sign if signed
+// is set. The fraction is kept to the nanosecond, with any further digits
+// dropped, rather than going through a float64 that can't hold it. Both
+// results have the number's sign.
+func parseSeconds(s string, signed bool) (sec, nsec int64, ok bool) {
+	neg := false
+	if signed && s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	whole, frac := s, ""
+	if i := strings.IndexAny(s, ".,"); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+		if !isDigits(frac) {
+			return 0, 0, false
+		}
+	}
+	if whole == "" && frac == "" || whole != "" && !isDigits(whole) {
+		return 0, 0, false
+	}
+
+	if whole != "" {
+		var err error
+		if sec, err = strconv.ParseInt(whole, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	for i := 0; i < 9; i++ {
+		nsec *= 10
+		if i < len(frac) {
+			nsec += int64(frac[i] - '0')
+		}
+	}
+
+	if neg {
+		sec, nsec = -sec, -nsec
+	}
+	return sec, nsec, true
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
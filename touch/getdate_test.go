@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetDate(t *testing.T) {
+	now := time.Date(2020, time.June, 15, 10, 30, 0, 0, time.Local)
+	day := func(d, h, m, s, ns int) time.Time {
+		return time.Date(2020, time.June, d, h, m, s, ns, time.Local)
+	}
+
+	tests := []struct {
+		in   string
+		want time.Time
+		ok   bool
+	}{
+		{"", now, true},
+		{"now", now, true},
+		{"@1700000000.123456789", time.Unix(1700000000, 123456789), true},
+		{"@1700000000,5", time.Unix(1700000000, 500000000), true},
+		{"@1.0000000019", time.Unix(1, 1), true},
+		{"@-1.5", time.Unix(-2, 500000000), true},
+		{"@+5", time.Unix(5, 0), true},
+		{"@.25", time.Unix(0, 250000000), true},
+		{"@", time.Time{}, false},
+		{"@1e3", time.Time{}, false},
+		{"@1.-5", time.Time{}, false},
+		{"@--1", time.Time{}, false},
+
+		{"2020-01-02T03:04:05Z", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), true},
+		{"2020-01-02", time.Date(2020, 1, 2, 0, 0, 0, 0, time.Local), true},
+		{"2020-01-02 13:04 utc", time.Date(2020, 1, 2, 13, 4, 0, 0, time.UTC), true},
+
+		{"12:30", day(15, 12, 30, 0, 0), true},
+		{"12:30:05.123456789", day(15, 12, 30, 5, 123456789), true},
+		{"1:30 pm", day(15, 13, 30, 0, 0), true},
+		{"12:00 am", day(15, 0, 0, 0, 0), true},
+		{"-1:30", time.Time{}, false},
+		{"+1:30", time.Time{}, false},
+		{"1:-30", time.Time{}, false},
+		{"1:30:-5", time.Time{}, false},
+		{"24:00", time.Time{}, false},
+		{"12:60", time.Time{}, false},
+		{"12:30:61", time.Time{}, false},
+
+		{"yesterday", day(14, 10, 30, 0, 0), true},
+		{"tomorrow 9:00", day(16, 9, 0, 0, 0), true},
+		{"2 hours ago", day(15, 8, 30, 0, 0), true},
+		{"next week", day(22, 10, 30, 0, 0), true},
+		{"last day", day(14, 10, 30, 0, 0), true},
+		{"-1day", day(14, 10, 30, 0, 0), true},
+		{"+90 minutes", day(15, 12, 0, 0, 0), true},
+		{"3 fortnights", time.Date(2020, time.July, 27, 10, 30, 0, 0, time.Local), true},
+
+		{"bogus", time.Time{}, false},
+		{"2 parsecs", time.Time{}, false},
+		{"next", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, err := getDate(tt.in, now)
+		if (err == nil) != tt.ok {
+			t.Errorf("getDate(%q) error = %v, want ok = %v", tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && !got.Equal(tt.want) {
+			t.Errorf("getDate(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
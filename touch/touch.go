@@ -1,43 +1,306 @@
 /*
+	Go touch -- change file timestamps
+
+	Copyright (C) 2015 Eric Lagergren
+
 	This program is free software: you can redistribute it and/or modify
-	it under the terms of the GNU General Public License version 3 as
-	published by the Free Software Foundation.
-	
+	it under the terms of the GNU General Public License as published by
+	the Free Software Foundation, either version 3 of the License, or
+	(at your option) any later version.
+
 	This program is distributed in the hope that it will be useful,
 	but WITHOUT ANY WARRANTY; without even the implied warranty of
 	MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
 	GNU General Public License for more details.
-	
+
 	You should have received a copy of the GNU General Public License
 	along with this program.  If not, see <http://www.gnu.org/licenses/>.
 */
 
+/*
+	Written by Eric Lagergren <ericscottlagergren@gmail.com>
+	Inspired by GNU's touch, which was written by
+	Paul Rubin, Arnold Robbins, Jim Kingdon, David MacKenzie, and Randy Smith.
+*/
+
 package main
 
-import "os"
-import flag "github.com/ogier/pflag"
-import "time"
-import "log"
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/EricLagerg/go-coreutils/internal/quote"
+	flag "github.com/ogier/pflag"
+)
+
+const (
+	Help = `Usage: touch [OPTION]... FILE...
+Update the access and modification times of each FILE to the current time.
+
+A FILE argument that does not exist is created empty, unless -c or -h
+is supplied.
+
+Mandatory arguments to long options are mandatory for short options too.
+  -a                     change only the access time
+  -c, --no-create        do not create any files
+  -d, --date=STRING      parse STRING and use it instead of current time
+  -f                     (ignored)
+  -h, --no-dereference   affect each symbolic link instead of any referenced
+                         file (useful only on systems that can change the
+                         timestamps of a symlink)
+  -m                     change only the modification time
+  -r, --reference=FILE   use this file's times instead of current time
+  -t STAMP               use [[CC]YY]MMDDhhmm[.ss] instead of current time
+      --time=WORD        change the specified time:
+                           WORD is access, atime, or use: equivalent to -a
+                           WORD is modify or mtime: equivalent to -m
+      --help     display this help and exit
+      --version  output version information and exit
+
+Note that the -d and -t options accept different time-date formats.
+
+If the SOURCE_DATE_EPOCH environment variable is set, its value (seconds
+since the Epoch) is used as the current time.
+
+Report touch bugs to ericscottlagergren@gmail.com
+Go coreutils home page: <https://www.github.com/EricLagerg/go-coreutils/>
+`
+	Version = `touch (Go coreutils) 2.0
+Copyright (C) 2015 Eric Lagergren
+License GPLv3+: GNU GPL version 3 or later <http://gnu.org/licenses/gpl.html>.
+This is free software: you are free to change and redistribute it.
+There is NO WARRANTY, to the extent permitted by law.
+
+Written by Eric Lagergren <ericscottlagergren@gmail.com>
+`
+)
+
+// Which timestamps to change.
+const (
+	chAtime = 1 << iota
+	chMtime
+)
+
+var (
+	accessOnly = flag.BoolP("N1O1L1O1N1G1O1P1T1", "a", false, "change only the access time")
+	noCreate   = flag.BoolP("no-create", "c", false, "do not create any files")
+	dateStr    = flag.StringP("date", "d", "", "parse STRING instead of using current time")
+	_          = flag.BoolP("N1O1L1O1N1G1O1P1T2", "f", false, "(ignored)")
+	noDeref    = flag.BoolP("no-dereference", "h", false, "affect symlinks, not referenced files")
+	modifyOnly = flag.BoolP("N1O1L1O1N1G1O1P1T3", "m", false, "change only the modification time")
+	refFile    = flag.StringP("reference", "r", "", "use this file's times")
+	stamp      = flag.StringP("N1O1L1O1N1G1O1P1T4", "t", "", "use [[CC]YY]MMDDhhmm[.ss]")
+	timeWord   = flag.String("time", "", "change the specified time")
+	version    = flag.Bool("version", false, "print program's version")
+
+	fatal = log.New(os.Stderr, "touch: ", 0)
+)
+
+// now returns the current time, or SOURCE_DATE_EPOCH if it's set so that
+// build systems can produce reproducible timestamps. See
+// https://reproducible-builds.org/specs/source-date-epoch/
+func now() (time.Time, bool) {
+	env := os.Getenv("SOURCE_DATE_EPOCH")
+	if env == "" {
+		return time.Now(), false
+	}
+	sec, err := strconv.ParseInt(env, 10, 64)
+	if err != nil || sec < 0 {
+		fatal.Fatalf("invalid SOURCE_DATE_EPOCH '%s'\n", env)
+	}
+	return time.Unix(sec, 0), true
+}
+
+// posixTime parses the -t STAMP argument, [[CC]YY]MMDDhhmm[.ss], in local
+// time.
+func posixTime(s string) (time.Time, error) {
+	invalid := fmt.Errorf("invalid date format '%s'", s)
+
+	sec := 0
+	if i := len(s) - 3; i >= 0 && s[i] == '.' {
+		n, err := strconv.Atoi(s[i+1:])
+		if err != nil || !isDigits(s[i+1:]) || n > 60 {
+			return time.Time{}, invalid
+		}
+		sec = n
+		s = s[:i]
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return time.Time{}, invalid
+		}
+	}
+
+	num := func(i int) int {
+		n, _ := strconv.Atoi(s[i : i+2])
+		return n
+	}
+
+	cur, _ := now()
+	year := cur.Year()
+	switch len(s) {
+	case 8:
+	case 10:
+		// POSIX: 69-99 refers to the 20th century, 00-68 to the 21st.
+		year = num(0) + 1900
+		if year < 1969 {
+			year += 100
+		}
+		s = s[2:]
+	case 12:
+		year = num(0)*100 + num(2)
+		s = s[4:]
+	default:
+		return time.Time{}, invalid
+	}
+
+	mon, day, hour, min := num(0), num(2), num(4), num(6)
+	t := time.Date(year, time.Month(mon), day, hour, min, sec, 0, time.Local)
+
+	// time.Date normalizes out of range values (e.g. February 30th) so
+	// make sure we got back what we asked for.
+	if t.Month() != time.Month(mon) || t.Day() != day ||
+		t.Hour() != hour || t.Minute() != min {
+		return time.Time{}, invalid
+	}
+	return t, nil
+}
 
 func main() {
-	cFlag := flag.BoolP("no-create", "c", false, "do not create file")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s", Help)
+		os.Exit(1)
+	}
 	flag.Parse()
-	if len(flag.Args()) > 0 {
-		for i := 0; i < len(flag.Args()); i++ {
-			filename := flag.Arg(i)
-			_, err := os.Stat(filename)
-			if err == nil {
-				now := time.Now()
-				os.Chtimes(filename, now, now)
-			} else {
-				if !(*cFlag) {
-					f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE, 0644)
-					f.Close()
-					if err != nil {
-						log.Fatal(err)
-					}
-				}
+
+	if *version {
+		fmt.Printf("%s", Version)
+		os.Exit(0)
+	}
+
+	change := 0
+	if *accessOnly {
+		change |= chAtime
+	}
+	if *modifyOnly {
+		change |= chMtime
+	}
+	switch *timeWord {
+	case "":
+	case "access", "atime", "use":
+		change |= chAtime
+	case "modify", "mtime":
+		change |= chMtime
+	default:
+		fatal.Printf("invalid argument '%s' for '--time'\n", *timeWord)
+		fatal.Fatalln("Try 'touch --help' for more information.")
+	}
+	if change == 0 {
+		change = chAtime | chMtime
+	}
+
+	if *stamp != "" && (*dateStr != "" || *refFile != "") {
+		fatal.Fatalln("cannot specify times from more than one source")
+	}
+
+	if flag.NArg() == 0 {
+		fatal.Printf("missing file operand\n")
+		fatal.Fatalln("Try 'touch --help' for more information.")
+	}
+
+	// A zero time means "now", which lets the kernel pick the timestamp
+	// (UTIME_NOW) so users with write permission on a file they don't own
+	// can still touch it. SOURCE_DATE_EPOCH, if set, is used instead.
+	var atime, mtime time.Time
+	cur, fixed := now()
+	if fixed {
+		atime, mtime = cur, cur
+	}
+
+	if *refFile != "" {
+		var err error
+		atime, mtime, err = statTimes(*refFile, !*noDeref)
+		if err != nil {
+			fatal.Fatalf("failed to get attributes of %s: %v\n",
+				quote.Name(*refFile), err)
+		}
+	}
+
+	if *dateStr != "" {
+		if *refFile != "" {
+			a, err := getDate(*dateStr, atime)
+			if err != nil {
+				fatal.Fatalln(err)
+			}
+			m, err := getDate(*dateStr, mtime)
+			if err != nil {
+				fatal.Fatalln(err)
 			}
+			atime, mtime = a, m
+		} else {
+			t, err := getDate(*dateStr, cur)
+			if err != nil {
+				fatal.Fatalln(err)
+			}
+			atime, mtime = t, t
+		}
+	}
+
+	if *stamp != "" {
+		t, err := posixTime(*stamp)
+		if err != nil {
+			fatal.Fatalln(err)
+		}
+		atime, mtime = t, t
+	}
+
+	ok := 0
+	for _, name := range flag.Args() {
+		if !touch(name, atime, mtime, change) {
+			ok = 1
+		}
+	}
+	os.Exit(ok)
+}
+
+// touch creates name if needed and updates the timestamps selected by
+// change. It returns false if any part of that failed.
+func touch(name string, atime, mtime time.Time, change int) bool {
+	// Like GNU, don't give up if the open fails: directories and files
+	// we own but can't write to can still have their times changed.
+	var openErr error
+	if !*noCreate && !*noDeref {
+		file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE, 0666)
+		if err == nil {
+			file.Close()
+		} else {
+			openErr = err
+		}
+	}
+
+	if err := setTimes(name, atime, mtime, change, !*noDeref); err != nil {
+		if openErr != nil {
+			fatal.Printf("cannot touch %s: %v\n", quote.Name(name), pathErr(openErr))
+			return false
 		}
+		if *noCreate && os.IsNotExist(err) {
+			return true
+		}
+		fatal.Printf("setting times of %s: %v\n", quote.Name(name), pathErr(err))
+		return false
+	}
+	return true
+}
+
+// pathErr strips the *os.PathError wrapping so diagnostics don't repeat
+// the file name.
+func pathErr(err error) error {
+	if e, ok := err.(*os.PathError); ok {
+		return e.Err
 	}
+	return err
 }
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPosixTime(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+		ok   bool
+	}{
+		{"202001021304", time.Date(2020, 1, 2, 13, 4, 0, 0, time.Local), true},
+		{"202001021304.05", time.Date(2020, 1, 2, 13, 4, 5, 0, time.Local), true},
+		{"2001021304", time.Date(2020, 1, 2, 13, 4, 0, 0, time.Local), true},
+		{"6901021304", time.Date(1969, 1, 2, 13, 4, 0, 0, time.Local), true},
+		{"6801021304", time.Date(2068, 1, 2, 13, 4, 0, 0, time.Local), true},
+
+		{"202001010000.+1", time.Time{}, false},
+		{"202001010000.-0", time.Time{}, false},
+		{"202001010000.61", time.Time{}, false},
+		{"202001010000.5", time.Time{}, false},
+		{"202002300000", time.Time{}, false},
+		{"202013010000", time.Time{}, false},
+		{"202001012400", time.Time{}, false},
+		{"20200101000", time.Time{}, false},
+		{"2020010100001", time.Time{}, false},
+		{"2020-1010000", time.Time{}, false},
+		{"", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, err := posixTime(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("posixTime(%q) error = %v, want ok = %v", tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && !got.Equal(tt.want) {
+			t.Errorf("posixTime(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
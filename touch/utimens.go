@@ -0,0 +1,10 @@
+// +build !windows,!darwin,!netbsd
+
+package main
+
+import "golang.org/x/sys/unix"
+
+const (
+	utimeNow  = unix.UTIME_NOW
+	utimeOmit = unix.UTIME_OMIT
+)
@@ -0,0 +1,8 @@
+package main
+
+// x/sys/unix doesn't export these for darwin; the values are from
+// <sys/stat.h>.
+const (
+	utimeNow  = -1
+	utimeOmit = -2
+)
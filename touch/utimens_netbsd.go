@@ -0,0 +1,8 @@
+package main
+
+// x/sys/unix doesn't export these for netbsd; the values are from
+// <sys/stat.h>.
+const (
+	utimeNow  = 1<<30 - 1
+	utimeOmit = 1<<30 - 2
+)
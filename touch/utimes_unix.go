@@ -0,0 +1,52 @@
+// +build !windows
+
+package main
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// timespec converts t to a unix.Timespec, mapping the zero time to
+// UTIME_NOW and unchanged timestamps to UTIME_OMIT.
+func timespec(t time.Time, change bool) unix.Timespec {
+	if !change {
+		return unix.Timespec{Nsec: utimeOmit}
+	}
+	if t.IsZero() {
+		return unix.Timespec{Nsec: utimeNow}
+	}
+	return unix.NsecToTimespec(t.UnixNano())
+}
+
+// setTimes sets name's access and/or modification times with
+// utimensat(2). If deref is false and name is a symbolic link, the
+// link's own timestamps are changed.
+func setTimes(name string, atime, mtime time.Time, change int, deref bool) error {
+	ts := []unix.Timespec{
+		timespec(atime, change&chAtime != 0),
+		timespec(mtime, change&chMtime != 0),
+	}
+
+	flags := 0
+	if !deref {
+		flags = unix.AT_SYMLINK_NOFOLLOW
+	}
+	return unix.UtimesNanoAt(unix.AT_FDCWD, name, ts, flags)
+}
+
+// statTimes returns name's access and modification times, following
+// symbolic links if deref is true.
+func statTimes(name string, deref bool) (atime, mtime time.Time, err error) {
+	var st unix.Stat_t
+	if deref {
+		err = unix.Stat(name, &st)
+	} else {
+		err = unix.Lstat(name, &st)
+	}
+	if err != nil {
+		return atime, mtime, err
+	}
+	return time.Unix(st.Atim.Unix()), time.Unix(st.Mtim.Unix()), nil
+}
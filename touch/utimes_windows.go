@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+// setTimes sets name's access and/or modification times. Windows can't
+// change a symbolic link's own timestamps, so deref must be true.
+func setTimes(name string, atime, mtime time.Time, change int, deref bool) error {
+	if !deref {
+		return &os.PathError{Op: "chtimes", Path: name,
+			Err: errors.New("operation not supported")}
+	}
+
+	now := time.Now()
+	if atime.IsZero() {
+		atime = now
+	}
+	if mtime.IsZero() {
+		mtime = now
+	}
+
+	if change != chAtime|chMtime {
+		oldA, oldM, err := statTimes(name, true)
+		if err != nil {
+			return err
+		}
+		if change&chAtime == 0 {
+			atime = oldA
+		}
+		if change&chMtime == 0 {
+			mtime = oldM
+		}
+	}
+	return os.Chtimes(name, atime, mtime)
+}
+
+// statTimes returns name's access and modification times.
+func statTimes(name string, deref bool) (atime, mtime time.Time, err error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return atime, mtime, err
+	}
+	attr := info.Sys().(*syscall.Win32FileAttributeData)
+	return time.Unix(0, attr.LastAccessTime.Nanoseconds()), info.ModTime(), nil
+}
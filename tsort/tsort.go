@@ -31,8 +31,7 @@ import (
 	"log"
 	"os"
 
-	"golang.org/x/sys/unix"
-
+	"github.com/EricLagerg/go-coreutils/internal/fadvise"
 	flag "github.com/ogier/pflag"
 )
 
@@ -353,7 +352,7 @@ func tsort(file *os.File) int {
 	scanner := bufio.NewScanner(reader)
 	scanner.Split(bufio.ScanWords)
 
-	unix.Fadvise(int(file.Fd()), 0, 0, unix.FADV_SEQUENTIAL)
+	fadvise.Sequential(file)
 
 	for scanner.Scan() {
 		k = root.searchItem(scanner.Text())
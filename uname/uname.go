@@ -1,3 +1,5 @@
+// +build !windows
+
 /*
    Go uname -- print system information
 
@@ -25,8 +27,6 @@
    namely the syscalls
 */
 
-// +build linux
-
 package main
 
 import (
@@ -36,7 +36,8 @@ import (
 	"os"
 	"runtime"
 	"strings"
-	"syscall"
+
+	"golang.org/x/sys/unix"
 )
 
 const (
@@ -84,7 +85,7 @@ var (
 	operatingSystem = flag.BoolP("operating-system", "o", false, "Operating system")
 	version         = flag.BoolP("version", "", false, "print program's version")
 
-	name syscall.Utsname
+	name unix.Utsname
 )
 
 type info struct {
@@ -98,29 +99,39 @@ type info struct {
 	os        string
 }
 
+// Proc returns the processor's model name, or "" if it isn't known.
+// Only Linux has CPU_INFO.
 func Proc() string {
-	c, _ := ioutil.ReadFile(CPU_INFO)
+	c, err := ioutil.ReadFile(CPU_INFO)
+	if err != nil {
+		return ""
+	}
 	line := strings.Split(string(c), "\n")
+	if len(line) < 5 || len(line[4]) < 13 {
+		return ""
+	}
 	return string(line[4][13:])
 }
 
-func IntToString(a [65]int8) string {
-	tmp := [65]byte{}
-	i := 0
-	for ; a[i] != 0; i++ {
-		tmp[i] = uint8(a[i])
+// CString converts a NUL-terminated Utsname field. The fields' sizes
+// differ between systems.
+func CString(a []byte) string {
+	for i, c := range a {
+		if c == 0 {
+			return string(a[:i])
+		}
 	}
-	return string(tmp[:i])
+	return string(a)
 }
 
 func GenInfo() *info {
-	_ = syscall.Uname(&name)
+	_ = unix.Uname(&name)
 	return &info{
-		kname:     IntToString(name.Sysname),
-		node:      IntToString(name.Nodename),
-		release:   IntToString(name.Release),
-		kversion:  IntToString(name.Version),
-		machine:   IntToString(name.Machine),
+		kname:     CString(name.Sysname[:]),
+		node:      CString(name.Nodename[:]),
+		release:   CString(name.Release[:]),
+		kversion:  CString(name.Version[:]),
+		machine:   CString(name.Machine[:]),
 		processor: Proc(),
 		os:        GOOS,
 	}
@@ -149,11 +160,7 @@ func main() {
 		return
 	}
 	if *kernelName {
-		if GOOS == "linux" {
-			fmt.Printf("%s ", "Linux")
-		} else {
-			fmt.Printf("%s ", GOOS)
-		}
+		fmt.Printf("%s ", uname.kname)
 	}
 	if *nodeName {
 		hn, err := os.Hostname()
@@ -188,7 +195,8 @@ func main() {
 		if uname.os == "linux" {
 			fmt.Printf("%s ", "GNU/Linux")
 		} else {
-			fmt.Printf("%s ", uname.os)
+			// The BSDs and Darwin report their own name, e.g. "FreeBSD".
+			fmt.Printf("%s ", uname.kname)
 		}
 	}
 	fmt.Println()
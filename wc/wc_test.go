@@ -76,3 +76,66 @@ func TestWC(t *testing.T) {
 	}
 
 }
+
+func TestWCMultibyte(t *testing.T) {
+	const file = "test_files/multibyte_mixed.txt"
+
+	defer func(mb, l, w, c, b, ll bool, width int) {
+		multibyte = mb
+		*printLines, *printWords, *printChars = l, w, c
+		*printBytes, *printLineLength = b, ll
+		numberWidth = width
+	}(multibyte, *printLines, *printWords, *printChars,
+		*printBytes, *printLineLength, numberWidth)
+	defer func(l, w, c, b, ll int64) {
+		totalLines, totalWords, totalChars = l, w, c
+		totalBytes, maxLineLength = b, ll
+	}(totalLines, totalWords, totalChars, totalBytes, maxLineLength)
+
+	multibyte = true
+	*printLines = false
+	*printWords = true
+	*printChars = true
+	*printBytes = true
+	*printLineLength = true
+
+	fs := getFileStatus(1, []string{file})
+	numberWidth = findNumberWidth(1, fs)
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+
+	wcFile(file, fs[0])
+
+	outC := make(chan string)
+	go func() {
+		var b bytes.Buffer
+		_, err := io.Copy(&b, r)
+		r.Close()
+		if err != nil {
+			t.Error(err)
+		}
+		outC <- b.String()
+	}()
+
+	w.Close()
+	os.Stdout = stdout
+	out := <-outC
+
+	// Wide characters, combining marks, and invalid or truncated
+	// sequences should be handled the same way as GNU in a UTF-8 locale.
+	wc := exec.Command("wc", "-wmcL", file)
+	wc.Env = append(os.Environ(), "LC_ALL=C.UTF-8")
+	b, err := wc.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out != string(b) {
+		t.Fatalf("Got:\n%s\n\nExpected:\n%s\n", out, b)
+	}
+}
@@ -1,3 +1,5 @@
+// +build !windows
+
 /*
 	Go wc - print the lines, words, bytes, and characters in files
 
@@ -38,8 +40,11 @@ import (
 
 	"github.com/EricLagerg/go-gnulib/sysinfo"
 	"github.com/EricLagerg/go-gnulib/ttyname"
-	"golang.org/x/sys/unix"
 
+	"github.com/EricLagerg/go-coreutils/internal/closeout"
+	"github.com/EricLagerg/go-coreutils/internal/fadvise"
+	"github.com/EricLagerg/go-coreutils/internal/noatime"
+	"github.com/EricLagerg/go-coreutils/internal/quote"
 	flag "github.com/ogier/pflag"
 )
 
@@ -66,6 +71,8 @@ the following order: newline, word, character, byte, maximum line length.
       --files0-from=F    read input from NUL-terminated string inside F
                          * If F is - then read names from standard input
   -L, --max-line-length  print the length of the longest line
+      --total=WHEN       when to print a line with total counts;
+                           WHEN can be: auto, always, only, never
   -w, --words            print the word counts
   -t, --tab              change tab width
   -h, --help             display this help and exit
@@ -114,6 +121,7 @@ var (
 	printBytes      = flag.BoolP("bytes", "c", false, "")
 	printLineLength = flag.BoolP("max-line-length", "L", false, "")
 	filesFrom       = flag.String("files0-from", "", "")
+	totalMode       = flag.String("total", "auto", "")
 	tabWidth        = flag.Int64P("tab", "t", 8, "")
 	constVersion    = flag.BoolP("unicode-version", "u", false, "")
 	version         = flag.BoolP("version", "v", false, "")
@@ -121,11 +129,15 @@ var (
 	// fatal.Fatal helper
 	//fatal = log.New(os.Stderr, "", log.Lshortfile)
 	fatal = log.New(os.Stderr, "", 0)
+
+	// out buffers standard output. It's made in main, not here, so the
+	// tests can swap os.Stdout out from under it.
+	out *bufio.Writer
 )
 
 func count(s []byte, delim byte) int64 {
 	count := int64(0)
-	i, k := 0, 0
+	i := 0
 	for i < len(s) {
 		if s[i] != delim {
 			o := bytes.IndexByte(s[i:], delim)
@@ -161,7 +173,7 @@ func wc(file *os.File, cur int64, status *fstatus) int {
 	countComplicated := *printWords || *printLineLength
 
 	if !*printBytes || *printChars || *printLines || countComplicated {
-		unix.Fadvise(int(file.Fd()), 0, 0, unix.FADV_SEQUENTIAL)
+		fadvise.Sequential(file)
 	}
 
 	// If we simply want the bytes we can ignore the overhead of
@@ -216,14 +228,32 @@ func wc(file *os.File, cur int64, status *fstatus) int {
 			}
 		}
 	} else {
+		// Bytes of a multibyte character split across two reads, which
+		// are carried over to the front of the buffer.
+		carry := 0
+
 		for {
-			n, err := file.Read(buffer)
+			n, err := file.Read(buffer[carry:])
 			numBytes += int64(n)
 
-			b := buffer[:n]
+			b := buffer[:carry+n]
+			carry = 0
 
 			for len(b) > 0 {
-				r, s := utf8.DecodeRune(b)
+				r, s := rune(b[0]), 1
+				if multibyte && r >= utf8.RuneSelf {
+					if !utf8.FullRune(b) && err == nil {
+						carry = copy(buffer, b)
+						break
+					}
+
+					// Like GNU, an invalid sequence is a byte but not
+					// a character.
+					if r, s = utf8.DecodeRune(b); r == utf8.RuneError && s == 1 {
+						b = b[1:]
+						continue
+					}
+				}
 
 				switch r {
 				case NewLine:
@@ -249,9 +279,14 @@ func wc(file *os.File, cur int64, status *fstatus) int {
 					words += inWord
 					inWord = 0
 				default:
-					if unicode.IsPrint(r) {
-						linePos++
-						inWord = 1
+					if w := runeWidth(r); w >= 0 {
+						linePos += int64(w)
+						if multibyte && unicode.IsSpace(r) {
+							words += inWord
+							inWord = 0
+						} else {
+							inWord = 1
+						}
 					}
 				}
 
@@ -273,7 +308,9 @@ func wc(file *os.File, cur int64, status *fstatus) int {
 		words += inWord
 	}
 
-	writeCounts(lines, words, chars, numBytes, lineLength, file.Name())
+	if *totalMode != "only" {
+		writeCounts(lines, words, chars, numBytes, lineLength, file.Name())
+	}
 
 	totalBytes += numBytes
 	totalChars += chars
@@ -293,13 +330,16 @@ func wcFile(name string, status *fstatus) int {
 			return wc(os.Stdin, -1, status)
 		}
 	} else {
-		fi, err := os.Open(name)
+		fi, err := noatime.Open(name)
 		if err != nil && err == err.(*os.PathError) {
-			fatal.Printf("%s No such file or directory\n", name)
+			fatal.Printf("%s: No such file or directory\n", quote.File(name))
 			return 1
 		}
 
 		ok := wc(fi, 0, status)
+
+		// Whatever we just read isn't likely to be read again soon.
+		fadvise.DontNeed(fi)
 		if err := fi.Close(); err != nil {
 			return 1
 		}
@@ -329,25 +369,18 @@ func getFileList(name string, size int64) []string {
 	// we just scan the entire buffer and allocate space for each string
 	// in one swoop. AFAIK it's why GNUs's wc uses physmem_available() / 2,
 	// so that it can hold the file twice in memory.
-	n, indices := count(buf, NullByte)
+	n := count(buf, NullByte)
 	list := make([]string, n)
 
-	cur := 0
-	for i, v := range indices {
-		fmt.Println(buf[cur:v])
-		list[i] = string(buf[cur:v])
-		cur = v
-	}
+	j, k := 0, 0
+	for i := 0; i < len(buf); i++ {
+		if buf[i] == NullByte {
+			list[k] = string(buf[j:i])
 
-	// j, k := 0, 0
-	// for i := 0; i < len(buf); i++ {
-	// 	if buf[i] == NullByte {
-	// 		list[k] = string(buf[j:i])
-
-	// 		j = i + 1
-	// 		k++
-	// 	}
-	// }
+			j = i + 1
+			k++
+		}
+	}
 
 	return list
 }
@@ -357,27 +390,42 @@ func writeCounts(lines, words, chars, numBytes, lineLength int64, fname string)
 	const fmtIntSp = " %*d"
 	fmtInt := "%*d"
 
+	var w io.Writer = os.Stdout
+	if out != nil {
+		w = out
+	}
+
 	if *printLines {
-		fmt.Printf(fmtInt, numberWidth, lines)
+		fmt.Fprintf(w, fmtInt, numberWidth, lines)
 		fmtInt = fmtIntSp
 	}
 	if *printWords {
-		fmt.Printf(fmtInt, numberWidth, words)
+		fmt.Fprintf(w, fmtInt, numberWidth, words)
 		fmtInt = fmtIntSp
 	}
 	if *printChars {
-		fmt.Printf(fmtInt, numberWidth, chars)
+		fmt.Fprintf(w, fmtInt, numberWidth, chars)
 		fmtInt = fmtIntSp
 	}
 	if *printBytes {
-		fmt.Printf(fmtInt, numberWidth, numBytes)
+		fmt.Fprintf(w, fmtInt, numberWidth, numBytes)
 		fmtInt = fmtIntSp
 	}
 	if *printLineLength {
-		fmt.Printf(fmtInt, numberWidth, lineLength)
+		fmt.Fprintf(w, fmtInt, numberWidth, lineLength)
 		fmtInt = fmtIntSp
 	}
-	fmt.Printf(" %s\n", fname)
+	if fname != "" {
+		fmt.Fprintf(w, " %s", fname)
+	}
+	fmt.Fprintln(w)
+}
+
+// die is fatal.Fatalf for once counts may have been written, which gets
+// them out of out first so they aren't lost.
+func die(format string, a ...interface{}) {
+	closeout.Stdout(out)
+	fatal.Fatalf(format, a...)
 }
 
 func getFileStatus(n int, names []string) []*fstatus {
@@ -470,6 +518,8 @@ func main() {
 	}
 	flag.Parse()
 
+	out = bufio.NewWriter(os.Stdout)
+
 	if *constVersion {
 		fmt.Printf("Unicode Version: %s\n", unicode.Version)
 		os.Exit(0)
@@ -484,24 +534,23 @@ func main() {
 		*printWords = true
 	}
 
-	// This is a gross attempt to simulate this...
+	switch *totalMode {
+	case "auto", "always", "only", "never":
+	default:
+		fatal.Printf("invalid argument '%s' for '--total'\n", *totalMode)
+		fatal.Fatalln("Valid arguments are: 'auto', 'always', 'only', 'never'")
+	}
+
 	// (print_lines + print_words + print_chars +
 	//	print_bytes + print_linelength) == 1
-	//
-	// Since Go can't add booleans (e.g. false + true == 1)
-	// and checking that *only* one of 5 bool variables would be sloppy,
-	// we check the number of set flags and the remaining non-'print' flags
-	// which is a much smaller set of conditions to check
-	//
-	// 1 flag and it's --files0-from
-	if (flag.NFlag() == 1 && *filesFrom == "" && *tabWidth == 8) ||
-		// 2 flags and one's *filesFrom OR *tabWidth
-		(flag.NFlag() == 2 && (*filesFrom != "" || *tabWidth != 8)) ||
-		// 3 flags and two are *filesFrom AND *tabWidth
-		(flag.NFlag() == 3 && *filesFrom != "" && *tabWidth != 8) {
-
-		printOne = true
+	n := 0
+	for _, p := range []bool{*printLines, *printWords, *printChars,
+		*printBytes, *printLineLength} {
+		if p {
+			n++
+		}
 	}
+	printOne = n == 1
 
 	var (
 		ok         = 0           // dictates return status
@@ -550,7 +599,7 @@ func main() {
 			fi, err = os.Open(*filesFrom)
 		}
 		if err != nil {
-			fatal.Fatalf("cannot open: %s", *filesFrom)
+			die("cannot open: %s\n", *filesFrom)
 		}
 		defer fi.Close()
 
@@ -564,7 +613,7 @@ func main() {
 						break
 					}
 				} else {
-					fatal.Fatalln(err)
+					die("%v\n", err)
 				}
 			}
 
@@ -597,11 +646,27 @@ func main() {
 		total = true
 	}
 
+	switch *totalMode {
+	case "always":
+		total = true
+	case "never":
+		total = false
+	case "only":
+		// Just the numbers, there's nothing to tell them apart from.
+		writeCounts(totalLines, totalWords,
+			totalChars, totalBytes, maxLineLength, "")
+		total = false
+	}
+
 	if total {
 		writeCounts(totalLines, totalWords,
 			totalChars, totalBytes, maxLineLength, "total")
 	}
 
+	if err := closeout.Stdout(out); err != nil {
+		fatal.Fatalln(err)
+	}
+
 	// return status
 	os.Exit(ok)
 }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+// multibyte is true if input should be decoded as UTF-8 rather than
+// treated as one character per byte.
+var multibyte = utf8Locale()
+
+// utf8Locale reports whether the locale's character encoding is UTF-8,
+// using the usual LC_ALL > LC_CTYPE > LANG precedence. An unset locale
+// means "C". Windows has no such variables, so always decode UTF-8 there.
+func utf8Locale() bool {
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			v = strings.ToLower(v)
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+	return false
+}
+
+// Ranges of East Asian Wide and Fullwidth characters, which take two
+// columns on a terminal.
+var wideTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x1100, 0x115f, 1},
+		{0x231a, 0x231b, 1},
+		{0x2329, 0x232a, 1},
+		{0x23e9, 0x23ec, 1},
+		{0x25fd, 0x25fe, 1},
+		{0x2614, 0x2615, 1},
+		{0x2e80, 0x303e, 1},
+		{0x3041, 0x33ff, 1},
+		{0x3400, 0x4dbf, 1},
+		{0x4e00, 0x9fff, 1},
+		{0xa000, 0xa4cf, 1},
+		{0xa960, 0xa97f, 1},
+		{0xac00, 0xd7a3, 1},
+		{0xf900, 0xfaff, 1},
+		{0xfe10, 0xfe19, 1},
+		{0xfe30, 0xfe6f, 1},
+		{0xff00, 0xff60, 1},
+		{0xffe0, 0xffe6, 1},
+	},
+	R32: []unicode.Range32{
+		{0x16fe0, 0x16fe4, 1},
+		{0x17000, 0x18cd5, 1},
+		{0x1b000, 0x1b2ff, 1},
+		{0x1f004, 0x1f004, 1},
+		{0x1f0cf, 0x1f0cf, 1},
+		{0x1f18e, 0x1f18e, 1},
+		{0x1f191, 0x1f19a, 1},
+		{0x1f200, 0x1f251, 1},
+		{0x1f300, 0x1f64f, 1},
+		{0x1f680, 0x1f6ff, 1},
+		{0x1f900, 0x1f9ff, 1},
+		{0x1fa70, 0x1faff, 1},
+		{0x20000, 0x2fffd, 1},
+		{0x30000, 0x3fffd, 1},
+	},
+}
+
+// runeWidth returns the number of columns r occupies, like wcwidth(3),
+// or -1 if r isn't printable.
+func runeWidth(r rune) int {
+	if !multibyte {
+		if r >= 0x20 && r < 0x7f {
+			return 1
+		}
+		return -1
+	}
+
+	switch {
+	case r == 0xad: // SOFT HYPHEN is printed
+		return 1
+	case !unicode.IsPrint(r) && !unicode.IsSpace(r) && !unicode.Is(unicode.Cf, r):
+		return -1
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf),
+		r >= 0x1160 && r <= 0x11ff, // Hangul medial vowels and final consonants
+		r == 0x200b:
+		return 0
+	case unicode.Is(wideTable, r):
+		return 2
+	}
+	return 1
+}
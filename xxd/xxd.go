@@ -18,6 +18,7 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/EricLagerg/go-coreutils/internal/closeout"
 	flag "github.com/ogier/pflag"
 )
 
@@ -788,7 +789,6 @@ func main() {
 	} else {
 		outFile = os.Stdout
 	}
-	defer outFile.Close()
 
 	switch true {
 	case *binary:
@@ -802,7 +802,6 @@ func main() {
 	}
 
 	out := bufio.NewWriter(outFile)
-	defer out.Flush()
 
 	if *reverse {
 		if err := xxdReverse(inFile, out); err != nil {
@@ -813,4 +812,16 @@ func main() {
 			log.Fatalln(err)
 		}
 	}
+
+	if outFile == os.Stdout {
+		err = closeout.Stdout(out)
+	} else if err = out.Flush(); err == nil {
+		err = outFile.Close()
+	}
+	if err != nil {
+		if outFile != os.Stdout {
+			err = closeout.WriteError(err)
+		}
+		log.Fatalln(err)
+	}
 }